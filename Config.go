@@ -0,0 +1,248 @@
+package go_log
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReadConfig
+//
+//	@Description: 从json或yaml文件中读取配置，根据扩展名.yaml、.yml识别yaml，其余按json解析；
+//	yaml与json使用相同的字段名（GoLogConfig的json标签），日志级别不区分大小写
+//	@param path 配置文件路径
+//	@return *GoLogConfig
+//	@return error
+func ReadConfig(path string) (*GoLogConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var m map[string]any
+		if err = yaml.Unmarshal(data, &m); err != nil {
+			return nil, errors.New("parse config " + path + " failed,err:" + err.Error())
+		}
+		if data, err = json.Marshal(m); err != nil {
+			return nil, errors.New("parse config " + path + " failed,err:" + err.Error())
+		}
+	}
+	config := &GoLogConfig{}
+	if err = json.Unmarshal(data, config); err != nil {
+		return nil, errors.New("parse config " + path + " failed,err:" + err.Error())
+	}
+	return config, nil
+}
+
+// LoadConfig
+//
+//	@Description: 从json或yaml文件中读取配置并创建日志 @See ReadConfig
+//	@param path 配置文件路径
+//	@return *GoLog
+//	@return error
+func LoadConfig(path string) (*GoLog, error) {
+	config, err := ReadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return newGoLog(config)
+}
+
+// ApplyConfig
+//
+//	@Description: 将配置应用到运行中的日志，包括日志级别、短日志、控制台、颜色、格式化器、vmodule、管道写满时的处理策略、各输出端的级别及滚动配置；
+//	MsgChan、BufferSize、Writer、WriterLogLevel、LogDir、LogName、RollNameTemplate、CurrentLink、ReopenOnSighup、Sinks需要重新创建日志才能生效；格式化器会覆盖SetLogFormatter设置的值
+//	@receiver g
+//	@param config
+//	@return error 配置不合法时不做任何修改
+func (g *GoLog) ApplyConfig(config *GoLogConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if g.fileSink != nil {
+		// 滚动配置还需满足运行中文件输出端的文件名模板，模板在创建后不变
+		if _, err := g.fileSink.validateRoll(config.RollLogByTime, config.RollLogBySize); err != nil {
+			return err
+		}
+	}
+	c := config.newLogConfig()
+	g.Lock()
+	g.config.Store(c)
+	g.Unlock()
+	g.consoleSink.SetLevel(config.ConsoleLogLevel)
+	g.consoleSink.SetColorEnable(config.ColorEnable)
+	if g.fileSink != nil {
+		g.fileSink.SetLevel(config.FileLogLevel)
+		if err := g.fileSink.SetRollLocation(config.RollLocation, config.RollTimeLayout); err != nil {
+			return err
+		}
+		if err := g.fileSink.SetRoll(config.RollLogByTime, config.RollLogBySize); err != nil {
+			return err
+		}
+		if err := g.fileSink.SetRetention(config.MaxBackups, config.MaxAge, config.MaxTotalSize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WatchConfig
+//
+//	@Description: 监听配置文件，文件变化时重新读取并应用到运行中的日志 @See ApplyConfig；日志销毁后自动停止监听
+//	@receiver g
+//	@param path 配置文件路径
+//	@param interval 检查间隔，小于等于0时为1秒
+//	@return func() 停止监听
+//	@return error 配置文件不存在
+func (g *GoLog) WatchConfig(path string, interval time.Duration) (func(), error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	done := make(chan struct{})
+	stopOnce := sync.Once{}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		modTime, size := info.ModTime(), info.Size()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if !g.running() {
+					return
+				}
+				info, err := os.Stat(path)
+				if err != nil || (info.ModTime().Equal(modTime) && info.Size() == size) {
+					continue
+				}
+				modTime, size = info.ModTime(), info.Size()
+				config, err := ReadConfig(path)
+				if err == nil {
+					err = g.ApplyConfig(config)
+				}
+				if err != nil {
+					_, _ = os.Stderr.WriteString("reload config " + path + " failed,err:" + err.Error() + "\n")
+				}
+			}
+		}
+	}()
+	return func() {
+		stopOnce.Do(func() {
+			close(done)
+		})
+	}, nil
+}
+
+// Validate
+//
+//	@Description: 校验配置，包括各日志级别、滚动时间、滚动时区、时间块格式、文件名模板、符号链接、滚动大小、保留策略、缓冲区长度、管道写满时的处理策略、输出格式、转换模式及vmodule
+//	@receiver config
+//	@return error
+func (config *GoLogConfig) Validate() error {
+	for _, level := range []LogLevel{config.LogLevel, config.ConsoleLogLevel, config.FileLogLevel, config.WriterLogLevel} {
+		if level != "" && level.LevelNum() < 0 {
+			return errors.New("unknown log level " + string(level))
+		}
+	}
+	schedule, err := parseRollSchedule(config.RollLogByTime)
+	if err != nil {
+		return err
+	}
+	if _, err := parseRollLocation(config.RollLocation); err != nil {
+		return err
+	}
+	if _, err := parseRollTimeLayout(config.RollTimeLayout); err != nil {
+		return err
+	}
+	template, err := newRollTemplate(config.RollNameTemplate, config.LogName, schedule != nil, config.RollLogBySize)
+	if err != nil {
+		return err
+	}
+	if err = validateCurrentLink(config.CurrentLink, config.LogName, template != nil); err != nil {
+		return err
+	}
+	if config.RollLogBySize < 0 {
+		return errors.New("invalid roll_log_by_size " + strconv.FormatInt(config.RollLogBySize, 10))
+	}
+	if _, err := validateRetention(config.MaxBackups, config.MaxAge, config.MaxTotalSize); err != nil {
+		return err
+	}
+	if config.BufferSize < 0 {
+		return errors.New("invalid buffer_size " + strconv.Itoa(config.BufferSize))
+	}
+	if _, err := parseOverflowPolicy(string(config.OverflowPolicy)); err != nil {
+		return err
+	}
+	if _, err := parseBlockTimeout(config.BlockTimeout); err != nil {
+		return err
+	}
+	if _, err := parseVModule(config.VModule); err != nil {
+		return err
+	}
+	if _, _, err := config.newFormatter(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// newLogConfig
+//
+//	@Description: 根据已校验的配置创建运行时配置快照
+//	@receiver config
+//	@return *logConfig
+func (config *GoLogConfig) newLogConfig() *logConfig {
+	vm, _ := parseVModule(config.VModule)
+	formatter, pattern, _ := config.newFormatter()
+	overflowPolicy, _ := parseOverflowPolicy(string(config.OverflowPolicy))
+	blockTimeout, _ := parseBlockTimeout(config.BlockTimeout)
+	return &logConfig{
+		logLevel:       config.LogLevel,
+		shortLogEnable: config.ShortLogEnable,
+		consoleEnable:  config.ConsoleEnable,
+		logFormatter:   formatter,
+		pattern:        pattern,
+		vmodule:        vm,
+		overflowPolicy: overflowPolicy,
+		blockTimeout:   blockTimeout,
+	}
+}
+
+// newFormatter
+//
+//	@Description: 根据LogFormat创建格式化器或转换模式
+//	@receiver config
+//	@return func(entry *LogEntity) string
+//	@return *PatternLayout
+//	@return error
+func (config *GoLogConfig) newFormatter() (func(entry *LogEntity) string, *PatternLayout, error) {
+	switch config.LogFormat {
+	case "", LogFormatText:
+		if config.Pattern == "" {
+			return nil, nil, nil
+		}
+		pattern, err := CompilePattern(config.Pattern)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, pattern, nil
+	case LogFormatJson:
+		return NewJsonFormatter(config.JsonFormat), nil, nil
+	case LogFormatLogfmt:
+		return NewLogfmtFormatter(config.LogfmtFormat), nil, nil
+	default:
+		return nil, nil, errors.New("unknown log format " + string(config.LogFormat))
+	}
+}
@@ -0,0 +1,232 @@
+package go_log
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ConfigFromEnv
+//
+//	@Description: 在默认配置的基础上读取环境变量 @See DefaultConfig，如prefix为GOLOG时读取：
+//	GOLOG_LEVEL、GOLOG_SHORT_LOG、GOLOG_BUFFER_SIZE、GOLOG_OVERFLOW_POLICY、GOLOG_BLOCK_TIMEOUT、GOLOG_CONSOLE、GOLOG_COLOR、GOLOG_CONSOLE_LEVEL、GOLOG_DIR、GOLOG_NAME、
+//	GOLOG_FILE_LEVEL、GOLOG_ROLL_BY_TIME、GOLOG_ROLL_LOCATION、GOLOG_ROLL_TIME_LAYOUT、GOLOG_ROLL_NAME_TEMPLATE、GOLOG_CURRENT_LINK、GOLOG_REOPEN_ON_SIGHUP、GOLOG_ROLL_BY_SIZE、GOLOG_MAX_BACKUPS、GOLOG_MAX_AGE、GOLOG_MAX_TOTAL_SIZE、GOLOG_FORMAT、GOLOG_PATTERN、GOLOG_VMODULE
+//	@param prefix 环境变量前缀，为空时不加前缀
+//	@return *GoLogConfig
+//	@return error 环境变量的值不合法
+func ConfigFromEnv(prefix string) (*GoLogConfig, error) {
+	config := DefaultConfig()
+	if err := config.LoadEnv(prefix); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// LoadEnv
+//
+//	@Description: 使用环境变量覆盖配置，未设置的环境变量保持原值 @See ConfigFromEnv
+//	@receiver config
+//	@param prefix 环境变量前缀，为空时不加前缀
+//	@return error 环境变量的值不合法
+func (config *GoLogConfig) LoadEnv(prefix string) error {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+	fs := flag.NewFlagSet(prefix, flag.ContinueOnError)
+	config.bindFlags(fs, func(name string) string {
+		return prefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	})
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		value, ok := os.LookupEnv(f.Name)
+		if !ok || err != nil {
+			return
+		}
+		if e := f.Value.Set(value); e != nil {
+			err = errors.New(f.Name + ": " + e.Error())
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return config.Validate()
+}
+
+// RegisterFlags
+//
+//	@Description: 将配置注册为命令行参数，flag的默认值为配置的当前值：
+//	-log-level、-log-short-log、-log-buffer-size、-log-overflow-policy、-log-block-timeout、-log-console、-log-color、-log-console-level、-log-dir、-log-name、
+//	-log-file-level、-log-roll-by-time、-log-roll-location、-log-roll-time-layout、-log-roll-name-template、-log-current-link、-log-reopen-on-sighup、-log-roll-by-size、-log-max-backups、-log-max-age、-log-max-total-size、-log-format、-log-pattern、-log-vmodule，
+//	日志级别、处理策略与时间在解析参数时校验
+//	@receiver config
+//	@param fs
+func (config *GoLogConfig) RegisterFlags(fs *flag.FlagSet) {
+	config.bindFlags(fs, func(name string) string {
+		return "log-" + name
+	})
+}
+
+// bindFlags
+//
+//	@Description: 将配置项绑定到FlagSet，环境变量与命令行参数共用
+//	@receiver config
+//	@param fs
+//	@param name 根据配置项名称生成flag名称
+func (config *GoLogConfig) bindFlags(fs *flag.FlagSet, name func(string) string) {
+	fs.Var(&config.LogLevel, name("level"), "log level: "+levelNames())
+	fs.BoolVar(&config.ShortLogEnable, name("short-log"), config.ShortLogEnable, "only log the file name of the caller")
+	fs.IntVar(&config.BufferSize, name("buffer-size"), config.BufferSize, "length of the log buffer")
+	fs.Var((*overflowPolicyValue)(&config.OverflowPolicy), name("overflow-policy"), "policy when the log buffer is full: block, block_timeout, drop_newest, drop_oldest or sync_write")
+	fs.Var((*blockTimeoutValue)(&config.BlockTimeout), name("block-timeout"), "timeout of the block_timeout policy, such as 100ms")
+	fs.BoolVar(&config.ConsoleEnable, name("console"), config.ConsoleEnable, "write logs to stdout")
+	fs.BoolVar(&config.ColorEnable, name("color"), config.ColorEnable, "colorful console output")
+	fs.Var(&config.ConsoleLogLevel, name("console-level"), "minimum level of the console output")
+	fs.StringVar(&config.LogDir, name("dir"), config.LogDir, "directory of the log file")
+	fs.StringVar(&config.LogName, name("name"), config.LogName, "name of the log file")
+	fs.Var(&config.FileLogLevel, name("file-level"), "minimum level of the log file")
+	fs.Var((*rollTimeValue)(&config.RollLogByTime), name("roll-by-time"), "roll the log file by time: a duration such as 5m, hourly, daily, weekly, monthly or a cron expression such as \"0 3 * * *\"")
+	fs.StringVar(&config.RollLocation, name("roll-location"), config.RollLocation, "time zone of the roll schedule, such as Asia/Shanghai")
+	fs.StringVar(&config.RollTimeLayout, name("roll-time-layout"), config.RollTimeLayout, "time layout in the name of rolled files, such as 20060102")
+	fs.StringVar(&config.RollNameTemplate, name("roll-name-template"), config.RollNameTemplate, "name template of log files, such as {dir}/{name}-{time:20060102-1504}.{index}{ext}")
+	fs.StringVar(&config.CurrentLink, name("current-link"), config.CurrentLink, "name of the symlink to the current log file")
+	fs.BoolVar(&config.ReopenOnSighup, name("reopen-on-sighup"), config.ReopenOnSighup, "reopen the log file on SIGHUP")
+	fs.Int64Var(&config.RollLogBySize, name("roll-by-size"), config.RollLogBySize, "roll the log file by size in KB")
+	fs.IntVar(&config.MaxBackups, name("max-backups"), config.MaxBackups, "maximum number of compressed log files to keep")
+	fs.Var((*maxAgeValue)(&config.MaxAge), name("max-age"), "maximum age of compressed log files, such as 168h")
+	fs.Int64Var(&config.MaxTotalSize, name("max-total-size"), config.MaxTotalSize, "maximum total size of compressed log files in KB")
+	fs.Var((*logFormatValue)(&config.LogFormat), name("format"), "log format: text, json or logfmt")
+	fs.StringVar(&config.Pattern, name("pattern"), config.Pattern, "conversion pattern of the text format")
+	fs.StringVar(&config.VModule, name("vmodule"), config.VModule, "per file log level, such as rotation*=DEBUG")
+}
+
+// levelNames
+//
+//	@Description: 获取全部日志级别名称，用于参数说明
+//	@return string
+func levelNames() string {
+	levels := Levels()
+	names := make([]string, len(levels))
+	for i, level := range levels {
+		names[i] = string(level)
+	}
+	return strings.Join(names, ", ")
+}
+
+// String
+//
+//	@Description: 获取日志级别名称
+//	@receiver l
+//	@return string
+func (l LogLevel) String() string {
+	return string(l)
+}
+
+// Set
+//
+//	@Description: 实现flag.Value，解析日志级别
+//	@receiver l
+//	@param s
+//	@return error
+func (l *LogLevel) Set(s string) error {
+	return l.UnmarshalText([]byte(s))
+}
+
+// rollTimeValue
+// @Description: 滚动时间参数，设置时校验
+type rollTimeValue string
+
+func (v *rollTimeValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return string(*v)
+}
+
+func (v *rollTimeValue) Set(s string) error {
+	if _, err := parseRollSchedule(s); err != nil {
+		return err
+	}
+	*v = rollTimeValue(s)
+	return nil
+}
+
+// maxAgeValue
+// @Description: 压缩文件的最长保留时间参数，设置时校验
+type maxAgeValue string
+
+func (v *maxAgeValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return string(*v)
+}
+
+func (v *maxAgeValue) Set(s string) error {
+	if _, err := validateRetention(0, s, 0); err != nil {
+		return err
+	}
+	*v = maxAgeValue(s)
+	return nil
+}
+
+// logFormatValue
+// @Description: 日志输出格式参数，设置时校验
+type logFormatValue LogFormat
+
+func (v *logFormatValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return string(*v)
+}
+
+func (v *logFormatValue) Set(s string) error {
+	switch format := LogFormat(strings.ToLower(strings.TrimSpace(s))); format {
+	case "", LogFormatText, LogFormatJson, LogFormatLogfmt:
+		*v = logFormatValue(format)
+		return nil
+	default:
+		return errors.New("unknown log format " + strconv.Quote(s))
+	}
+}
+
+// overflowPolicyValue
+// @Description: 管道写满时的处理策略参数，设置时校验
+type overflowPolicyValue OverflowPolicy
+
+func (v *overflowPolicyValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return string(*v)
+}
+
+func (v *overflowPolicyValue) Set(s string) error {
+	policy, err := parseOverflowPolicy(s)
+	if err != nil {
+		return err
+	}
+	*v = overflowPolicyValue(policy)
+	return nil
+}
+
+// blockTimeoutValue
+// @Description: block_timeout策略的超时时间参数，设置时校验
+type blockTimeoutValue string
+
+func (v *blockTimeoutValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return string(*v)
+}
+
+func (v *blockTimeoutValue) Set(s string) error {
+	if _, err := parseBlockTimeout(s); err != nil {
+		return err
+	}
+	*v = blockTimeoutValue(s)
+	return nil
+}
@@ -0,0 +1,111 @@
+package go_log
+
+import (
+	"context"
+	"sync"
+)
+
+// ContextExtractor
+// @Description: 上下文字段提取器，从context.Context中取出需要附加到日志中的字段（如trace id、租户、用户）
+type ContextExtractor func(ctx context.Context) Fields
+
+// registeredExtractor
+// @Description: 已注册的提取器，id用于取消注册
+type registeredExtractor struct {
+	id        uint64
+	extractor ContextExtractor
+}
+
+var extractorLock = sync.RWMutex{}
+var contextExtractors []registeredExtractor
+var extractorId uint64
+
+// RegisterContextExtractor
+//
+//	@Description: 注册上下文字段提取器，所有*Ctx方法输出日志时都会依次调用已注册的提取器
+//	@param extractor
+//	@return func() 取消本次注册，可重复调用；同一个提取器注册多次时需要分别取消
+func RegisterContextExtractor(extractor ContextExtractor) func() {
+	if extractor == nil {
+		return func() {}
+	}
+	extractorLock.Lock()
+	defer extractorLock.Unlock()
+	extractorId++
+	id := extractorId
+	contextExtractors = append(contextExtractors, registeredExtractor{id: id, extractor: extractor})
+	return func() {
+		extractorLock.Lock()
+		defer extractorLock.Unlock()
+		for i, registered := range contextExtractors {
+			if registered.id == id {
+				extractors := make([]registeredExtractor, 0, len(contextExtractors)-1)
+				extractors = append(extractors, contextExtractors[:i]...)
+				contextExtractors = append(extractors, contextExtractors[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// ContextValueExtractor
+//
+//	@Description: 创建一个按key取值的提取器，context中存在该key时以name为字段名输出
+//	@param key context中的key
+//	@param name 字段名
+//	@return ContextExtractor
+func ContextValueExtractor(key any, name string) ContextExtractor {
+	return func(ctx context.Context) Fields {
+		if value := ctx.Value(key); value != nil {
+			return Fields{{Key: name, Value: value}}
+		}
+		return nil
+	}
+}
+
+// extractContextFields
+//
+//	@Description: 调用所有已注册的提取器获取上下文字段
+//	@param ctx
+//	@return Fields
+func extractContextFields(ctx context.Context) Fields {
+	if ctx == nil {
+		return nil
+	}
+	extractorLock.RLock()
+	defer extractorLock.RUnlock()
+	var fields Fields
+	for _, registered := range contextExtractors {
+		fields = append(fields, registered.extractor(ctx)...)
+	}
+	return fields
+}
+
+type loggerKey struct{}
+
+// NewContext
+//
+//	@Description: 将日志放入context中，便于请求处理过程中沿调用栈传递带有上下文字段的日志
+//	@param ctx
+//	@param logger
+//	@return context.Context
+func NewContext(ctx context.Context, logger ILogger) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext
+//
+//	@Description: 从context中获取日志，不存在时返回单例日志 @See GetSingleGoLog
+//	@param ctx
+//	@return ILogger
+func FromContext(ctx context.Context) ILogger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(ILogger); ok && logger != nil {
+			return logger
+		}
+	}
+	return GetSingleGoLog()
+}
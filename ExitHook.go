@@ -0,0 +1,52 @@
+package go_log
+
+import (
+	"os"
+	"sync"
+)
+
+var exitHookLock = sync.Mutex{}
+var exitHooks []func()
+
+// RegisterExitHook
+//
+//	@Description: 注册退出钩子，Fatal级别日志在日志全部落盘后、退出进程前按注册顺序执行
+//	@param hook
+func RegisterExitHook(hook func()) {
+	if hook == nil {
+		return
+	}
+	exitHookLock.Lock()
+	defer exitHookLock.Unlock()
+	exitHooks = append(exitHooks, hook)
+}
+
+// runExitHooks
+//
+//	@Description: 按注册顺序执行退出钩子，单个钩子panic不影响其余钩子
+func runExitHooks() {
+	exitHookLock.Lock()
+	hooks := make([]func(), len(exitHooks))
+	copy(hooks, exitHooks)
+	exitHookLock.Unlock()
+	for _, hook := range hooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					_, _ = os.Stderr.WriteString("exit hook panic\n")
+				}
+			}()
+			hook()
+		}()
+	}
+}
+
+// exit
+//
+//	@Description: 销毁日志核心（子日志同样生效），执行退出钩子后以状态码1退出进程
+//	@receiver g
+func (g *GoLog) exit() {
+	g.destroy()
+	runExitHooks()
+	os.Exit(1)
+}
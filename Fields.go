@@ -0,0 +1,221 @@
+package go_log
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Field
+// @Description: 结构化日志字段
+type Field struct {
+	Key   string //字段名
+	Value any    //字段值
+}
+
+// Fields
+// @Description: 有序的结构化字段集合
+type Fields []Field
+
+// String
+//
+//	@Description: 以 key=value 的形式输出字段，多个字段之间以空格分隔
+//	@receiver f
+//	@return string
+func (f Fields) String() string {
+	if len(f) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	for i, field := range f {
+		if i > 0 {
+			builder.WriteByte(' ')
+		}
+		builder.WriteString(field.Key)
+		builder.WriteByte('=')
+		builder.WriteString(quoteIfNeeded(fieldValueString(field.Value)))
+	}
+	return builder.String()
+}
+
+// Map
+//
+//	@Description: 转换为map，重复的key以最后一个为准
+//	@receiver f
+//	@return map[string]any
+func (f Fields) Map() map[string]any {
+	m := make(map[string]any, len(f))
+	for _, field := range f {
+		m[field.Key] = field.Value
+	}
+	return m
+}
+
+// MarshalJSON
+//
+//	@Description: 按字段顺序序列化为json对象，便于自定义格式化器直接使用json.Marshal
+//	@receiver f
+//	@return []byte
+//	@return error
+func (f Fields) MarshalJSON() ([]byte, error) {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '{')
+	for i, field := range f {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		key, err := json.Marshal(field.Key)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, key...)
+		buf = append(buf, ':')
+		val := field.Value
+		if e, ok := val.(error); ok {
+			val = e.Error()
+		}
+		value, err := json.Marshal(val)
+		if err != nil {
+			value, _ = json.Marshal(fmt.Sprint(val))
+		}
+		buf = append(buf, value...)
+	}
+	buf = append(buf, '}')
+	return buf, nil
+}
+
+// toFields
+//
+//	@Description: 将交替出现的key、value转换为字段，key不是字符串时使用fmt.Sprint转换，缺失的value记为"!MISSING"
+//	@param keysAndValues
+//	@return Fields
+func toFields(keysAndValues []any) Fields {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+	fields := make(Fields, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		var key string
+		switch k := keysAndValues[i].(type) {
+		case string:
+			key = k
+		default:
+			key = fmt.Sprint(k)
+		}
+		if i+1 >= len(keysAndValues) {
+			fields = append(fields, Field{Key: key, Value: "!MISSING"})
+			break
+		}
+		fields = append(fields, Field{Key: key, Value: keysAndValues[i+1]})
+	}
+	return fields
+}
+
+// fieldValueString
+//
+//	@Description: 获取字段值的字符串表示，常见类型不经过反射
+//	@param value
+//	@return string
+func fieldValueString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case json.RawMessage:
+		return string(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int8:
+		return strconv.FormatInt(int64(v), 10)
+	case int16:
+		return strconv.FormatInt(int64(v), 10)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// freezeValue
+//
+//	@Description: 生成可变字段值的快照：实现了json.Marshaler或能被json序列化的值保存为json.RawMessage，保留json输出的结构；
+//	error、fmt.Stringer、[]byte以及无法序列化的值保存为字符串
+//	@param value
+//	@return any
+func freezeValue(value any) any {
+	switch v := value.(type) {
+	case json.Marshaler:
+		if bytes, err := v.MarshalJSON(); err == nil && json.Valid(bytes) {
+			return json.RawMessage(append([]byte(nil), bytes...))
+		}
+		return fieldValueString(v)
+	case []byte, error, fmt.Stringer:
+		return fieldValueString(v)
+	default:
+		if bytes, err := json.Marshal(v); err == nil {
+			return json.RawMessage(bytes)
+		}
+		return fieldValueString(v)
+	}
+}
+
+// quoteIfNeeded
+//
+//	@Description: 值为空或者包含空格、等号、引号、控制字符时加上引号
+//	@param s
+//	@return string
+func quoteIfNeeded(s string) string {
+	if needsQuote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// needsQuote
+//
+//	@Description: 判断值是否需要加上引号
+//	@param s
+//	@return bool
+func needsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f || r == utf8.RuneError {
+			return true
+		}
+	}
+	return false
+}
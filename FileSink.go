@@ -0,0 +1,905 @@
+package go_log
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileCheckInterval 检查日志文件是否被外部移动或替换的最小间隔
+const fileCheckInterval = time.Second
+
+// FileSinkConfig
+// @Description: 日志文件输出端配置，RollLogByTime、RollLogBySize可以同时设置，此时每个时间块滚动一次，时间块内超过大小时再按序号拆分
+type FileSinkConfig struct {
+	SinkConfig
+	LogDir         string            //日志存放目录
+	LogName        string            //日志文件名
+	RollLogByTime  string            //根据时间滚动 如:5m、daily、"0 3 * * *" @See GoLogConfig.RollLogByTime
+	RollLogBySize  int64             //根据文件大小滚动，单位KB，
+	RollLocation   string            //滚动时间使用的时区，如Asia/Shanghai，为空时使用本地时区
+	RollTimeLayout string            //滚动文件名中时间块的格式，为空时使用DateTimeLayout4
+	NameTemplate   string            //滚动文件名模板，如{dir}/{name}-{time:20060102-1504}.{index}{ext}，设置后日志直接写入按模板生成的文件 @See GoLogConfig.RollNameTemplate
+	CurrentLink    string            //指向当前日志文件的符号链接名，位于LogDir下，为空时不创建
+	MaxBackups     int               //最多保留的压缩文件个数，0表示不限制 @See GoLogConfig.MaxBackups
+	MaxAge         string            //压缩文件的最长保留时间，如168h，为空表示不限制
+	MaxTotalSize   int64             //压缩文件的总大小上限，单位KB，0表示不限制
+	OnDelete       func(path string) //删除压缩文件后的回调，可用于审计；在压缩协程中调用，可以通过同一个日志输出
+}
+
+// FileSink
+// @Description: 输出到日志文件的输出端，负责日志文件的滚动与压缩
+type FileSink struct {
+	sinkBase
+	logDir        string            //日志存放目录
+	logName       string            //日志文件路径（包含目录）
+	baseName      string            //日志文件名（不含目录），创建后不变
+	nameTemplate  *rollTemplate     //滚动文件名模板，为nil时滚动时重命名日志文件，创建后不变
+	currentLink   string            //指向当前日志文件的符号链接名
+	activeName    string            //使用模板时当前写入的文件路径
+	lastCheck     time.Time         //上一次检查文件是否被外部移动或替换的时间
+	rollSchedule  rollSchedule      //根据时间滚动，为nil时不按时间滚动
+	rollLogBySize int64             //根据文件大小滚动，单位KB，
+	rollLocation  *time.Location    //滚动时间使用的时区
+	rollLayout    string            //滚动文件名中时间块的格式，修改时同时持有retainLock
+	logFile       *os.File          //日志文件句柄
+	blockStart    time.Time         //当前文件所在时间块的开始时间，为零值时取文件修改时间所在的时间块
+	nextRoll      time.Time         //下一次按时间滚动的时间，为零值时根据blockStart计算
+	logFileSize   int64             //当前日志文件的大小
+	compressChan  chan struct{}     //压缩信号管道，有文件加入压缩队列时发出信号，关闭时压缩协程处理完队列后退出
+	compressQueue []string          //等待压缩的文件，由queueLock保护；消费协程只加入队列不等待压缩协程，避免OnDelete回调写日志时死锁
+	queueLock     sync.Mutex        //保护压缩队列
+	waiter        sync.WaitGroup    //等待压缩协程退出
+	retainLock    sync.Mutex        //保护保留策略，压缩协程不获取输出端的锁，压缩、清理不会阻塞写日志
+	maxBackups    int               //最多保留的压缩文件个数
+	maxAge        time.Duration     //压缩文件的最长保留时间
+	maxTotalSize  int64             //压缩文件的总大小上限，单位KB
+	onDelete      func(path string) //删除压缩文件后的回调
+}
+
+// NewFileSink
+//
+//	@Description: 创建日志文件输出端，文件在写入第一条日志时创建
+//	@param config
+//	@return *FileSink
+//	@return error 配置不合法
+func NewFileSink(config FileSinkConfig) (*FileSink, error) {
+	if config.LogName == "" {
+		return nil, errors.New("log name is empty")
+	}
+	if config.LogDir == "" {
+		config.LogDir = "./"
+	}
+	schedule, err := parseRollSchedule(config.RollLogByTime)
+	if err != nil {
+		return nil, err
+	}
+	location, err := parseRollLocation(config.RollLocation)
+	if err != nil {
+		return nil, err
+	}
+	layout, err := parseRollTimeLayout(config.RollTimeLayout)
+	if err != nil {
+		return nil, err
+	}
+	template, err := newRollTemplate(config.NameTemplate, config.LogName, schedule != nil, config.RollLogBySize)
+	if err != nil {
+		return nil, err
+	}
+	if err = validateCurrentLink(config.CurrentLink, config.LogName, template != nil); err != nil {
+		return nil, err
+	}
+	if config.RollLogBySize < 0 {
+		return nil, errors.New("invalid roll_log_by_size " + strconv.FormatInt(config.RollLogBySize, 10))
+	}
+	maxAge, err := validateRetention(config.MaxBackups, config.MaxAge, config.MaxTotalSize)
+	if err != nil {
+		return nil, err
+	}
+	f := &FileSink{
+		sinkBase:     newSinkBase(config.SinkConfig),
+		logDir:       config.LogDir,
+		logName:      filepath.Join(config.LogDir, config.LogName),
+		baseName:     filepath.Base(config.LogName),
+		nameTemplate: template,
+		currentLink:  config.CurrentLink,
+		rollLocation: location,
+		rollLayout:   layout,
+		maxBackups:   config.MaxBackups,
+		maxAge:       maxAge,
+		maxTotalSize: config.MaxTotalSize,
+		onDelete:     config.OnDelete,
+	}
+	f.setRoll(schedule, config.RollLogBySize)
+	return f, nil
+}
+
+// Write
+//
+//	@Description: 格式化并写入日志文件，按配置滚动文件
+//	@receiver f
+//	@param entry
+//	@return error
+func (f *FileSink) Write(entry *LogEntity) error {
+	f.Lock()
+	defer f.Unlock()
+	file := f.getLogFile()
+	if file == nil {
+		return nil
+	}
+	n, err := file.WriteString(f.format(entry))
+	f.logFileSize += int64(n)
+	return err
+}
+
+// Sync
+//
+//	@Description: 将日志文件刷入磁盘
+//	@receiver f
+//	@return error
+func (f *FileSink) Sync() error {
+	f.Lock()
+	defer f.Unlock()
+	if f.logFile == nil {
+		return nil
+	}
+	return f.logFile.Sync()
+}
+
+// Close
+//
+//	@Description: 刷盘并关闭日志文件，停止压缩协程（等待已提交的压缩任务完成）
+//	@receiver f
+//	@return error
+func (f *FileSink) Close() error {
+	f.Lock()
+	var err error
+	if f.logFile != nil {
+		err = f.logFile.Sync()
+		if e := f.logFile.Close(); err == nil {
+			err = e
+		}
+		f.logFile = nil
+	}
+	if f.compressChan != nil {
+		close(f.compressChan)
+		f.compressChan = nil
+	}
+	f.Unlock()
+	f.waiter.Wait()
+	return err
+}
+
+// Reopen
+//
+//	@Description: 关闭并重新打开日志文件，用于日志文件被logrotate等外部工具移动或删除后写入新文件
+//	@receiver f
+//	@return error
+func (f *FileSink) Reopen() error {
+	f.Lock()
+	defer f.Unlock()
+	if f.logFile != nil {
+		_ = f.logFile.Sync()
+		_ = f.logFile.Close()
+		f.logFile = nil
+	}
+	if f.getLogFile() == nil {
+		return errors.New("reopen logfile in " + f.logDir + " failed")
+	}
+	return nil
+}
+
+// SetRoll
+//
+//	@Description: 修改滚动配置
+//	@receiver f
+//	@param rollLogByTime 根据时间滚动，如5m、daily，为空表示不按时间滚动
+//	@param rollLogBySize 根据文件大小滚动，单位KB，0表示不按大小滚动
+//	@return error 配置不合法时不做任何修改
+func (f *FileSink) SetRoll(rollLogByTime string, rollLogBySize int64) error {
+	schedule, err := f.validateRoll(rollLogByTime, rollLogBySize)
+	if err != nil {
+		return err
+	}
+	f.Lock()
+	defer f.Unlock()
+	f.setRoll(schedule, rollLogBySize)
+	return nil
+}
+
+// validateRoll
+//
+//	@Description: 校验滚动配置，包括是否满足文件名模板的要求，不做修改
+//	@receiver f
+//	@param rollLogByTime
+//	@param rollLogBySize
+//	@return rollSchedule 解析后的滚动时间，不按时间滚动时为nil
+//	@return error
+func (f *FileSink) validateRoll(rollLogByTime string, rollLogBySize int64) (rollSchedule, error) {
+	schedule, err := parseRollSchedule(rollLogByTime)
+	if err != nil {
+		return nil, err
+	}
+	if rollLogBySize < 0 {
+		return nil, errors.New("invalid roll_log_by_size " + strconv.FormatInt(rollLogBySize, 10))
+	}
+	if f.nameTemplate != nil {
+		if err = f.nameTemplate.checkRoll(schedule != nil, rollLogBySize); err != nil {
+			return nil, err
+		}
+	}
+	return schedule, nil
+}
+
+// SetRollLocation
+//
+//	@Description: 修改滚动时间使用的时区与滚动文件名中时间块的格式，从当前时间块的下一次滚动开始生效
+//	@receiver f
+//	@param location 时区，如Asia/Shanghai，为空时使用本地时区
+//	@param layout 时间块的格式，为空时使用DateTimeLayout4
+//	@return error 配置不合法时不做任何修改
+func (f *FileSink) SetRollLocation(location, layout string) error {
+	loc, err := parseRollLocation(location)
+	if err != nil {
+		return err
+	}
+	if layout, err = parseRollTimeLayout(layout); err != nil {
+		return err
+	}
+	f.Lock()
+	defer f.Unlock()
+	f.retainLock.Lock()
+	defer f.retainLock.Unlock()
+	if loc.String() != f.rollLocation.String() {
+		f.rollLocation = loc
+		f.nextRoll = time.Time{}
+	}
+	f.rollLayout = layout
+	return nil
+}
+
+// SetRetention
+//
+//	@Description: 修改压缩文件的保留策略，在下一次滚动后生效
+//	@receiver f
+//	@param maxBackups 最多保留的压缩文件个数，0表示不限制
+//	@param maxAge 最长保留时间，如168h，为空表示不限制
+//	@param maxTotalSize 总大小上限，单位KB，0表示不限制
+//	@return error 配置不合法时不做任何修改
+func (f *FileSink) SetRetention(maxBackups int, maxAge string, maxTotalSize int64) error {
+	duration, err := validateRetention(maxBackups, maxAge, maxTotalSize)
+	if err != nil {
+		return err
+	}
+	f.retainLock.Lock()
+	defer f.retainLock.Unlock()
+	f.maxBackups = maxBackups
+	f.maxAge = duration
+	f.maxTotalSize = maxTotalSize
+	return nil
+}
+
+// validateRetention
+//
+//	@Description: 校验压缩文件的保留策略
+//	@param maxBackups
+//	@param maxAge
+//	@param maxTotalSize
+//	@return time.Duration 解析后的最长保留时间
+//	@return error
+func validateRetention(maxBackups int, maxAge string, maxTotalSize int64) (time.Duration, error) {
+	if maxBackups < 0 {
+		return 0, errors.New("invalid max_backups " + strconv.Itoa(maxBackups))
+	}
+	if maxTotalSize < 0 {
+		return 0, errors.New("invalid max_total_size " + strconv.FormatInt(maxTotalSize, 10))
+	}
+	if maxAge == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(maxAge)
+	if err != nil || duration <= 0 {
+		return 0, errors.New("invalid max_age " + strconv.Quote(maxAge))
+	}
+	return duration, nil
+}
+
+// setRoll
+//
+//	@Description: 修改滚动配置，开启滚动时启动压缩协程，调用方需持有锁
+//	@receiver f
+//	@param schedule
+//	@param rollLogBySize
+func (f *FileSink) setRoll(schedule rollSchedule, rollLogBySize int64) {
+	f.rollSchedule = schedule
+	f.rollLogBySize = rollLogBySize
+	f.nextRoll = time.Time{}
+	if f.compressChan != nil || (f.rollSchedule == nil && f.rollLogBySize == 0) {
+		return
+	}
+	f.compressChan = make(chan struct{}, 1)
+	f.waiter.Add(1)
+	go f.compressLogFile(f.compressChan)
+}
+
+// setLogDir
+//
+//	@Description: 修改日志存放目录，关闭当前的日志文件，下一条日志写入新目录
+//	@receiver f
+//	@param logDir
+func (f *FileSink) setLogDir(logDir string) {
+	f.Lock()
+	defer f.Unlock()
+	if f.logFile != nil {
+		_ = f.logFile.Sync()
+		_ = f.logFile.Close()
+		f.logFile = nil
+	}
+	f.logName = filepath.Join(logDir, filepath.Base(f.logName))
+	f.logDir = logDir
+	f.blockStart = time.Time{}
+	f.activeName = ""
+}
+
+// compressLogFile
+//
+//	@Description: 异步压缩文件，每次压缩完成后按保留策略清理压缩文件
+//	@receiver f
+//	@param compressChan
+//	@Author yuhao
+//	@Data 2023-02-28 11:30:47
+func (f *FileSink) compressLogFile(compressChan chan struct{}) {
+	defer f.waiter.Done()
+	for range compressChan {
+		for {
+			f.queueLock.Lock()
+			if len(f.compressQueue) == 0 {
+				f.queueLock.Unlock()
+				break
+			}
+			s := f.compressQueue[0]
+			f.compressQueue = f.compressQueue[1:]
+			f.queueLock.Unlock()
+			file, err := os.Open(s)
+			if err != nil {
+				_, _ = os.Stderr.WriteString("open file " + s + " failed,err:" + err.Error())
+				continue
+			}
+			err = Compress([]*os.File{file}, s+".zip")
+			if err != nil {
+				_, _ = os.Stderr.WriteString("Compress file " + s + ".zip" + " failed,err:" + err.Error())
+			}
+			_ = os.Remove(s)
+			f.cleanBackups(s)
+		}
+	}
+}
+
+// compress
+//
+//	@Description: 将滚动后的文件加入压缩队列，不等待压缩协程，调用方需持有锁
+//	@receiver f
+//	@param rolled
+func (f *FileSink) compress(rolled string) {
+	f.queueLock.Lock()
+	f.compressQueue = append(f.compressQueue, rolled)
+	f.queueLock.Unlock()
+	select {
+	case f.compressChan <- struct{}{}:
+	default:
+	}
+}
+
+// cleanBackups
+//
+//	@Description: 按MaxBackups、MaxAge、MaxTotalSize从旧到新删除压缩文件，只处理属于当前日志文件的压缩文件，在压缩协程中调用
+//	@receiver f
+//	@param rolled 刚压缩的滚动文件，即 日志文件路径-时间块、日志文件路径-序号 或 日志文件路径-时间块.序号
+func (f *FileSink) cleanBackups(rolled string) {
+	f.retainLock.Lock()
+	maxBackups, maxAge, maxTotalSize, onDelete, layout := f.maxBackups, f.maxAge, f.maxTotalSize, f.onDelete, f.rollLayout
+	f.retainLock.Unlock()
+	logDir := filepath.Dir(rolled)
+	if maxBackups == 0 && maxAge == 0 && maxTotalSize == 0 {
+		return
+	}
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		_, _ = os.Stderr.WriteString("ReadDir " + logDir + " failed,err:" + err.Error())
+		return
+	}
+	var backups []os.FileInfo
+	isBackup := f.backupMatcher(layout)
+	for _, entry := range entries {
+		if entry.IsDir() || !isBackup(entry.Name()) {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			backups = append(backups, info)
+		}
+	}
+	//  从新到旧排列
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].ModTime().Equal(backups[j].ModTime()) {
+			return backups[i].ModTime().After(backups[j].ModTime())
+		}
+		return backups[i].Name() > backups[j].Name()
+	})
+	now := time.Now()
+	var kept int
+	var totalSize int64
+	for _, info := range backups {
+		if (maxBackups == 0 || kept < maxBackups) &&
+			(maxAge == 0 || now.Sub(info.ModTime()) <= maxAge) &&
+			(maxTotalSize == 0 || totalSize+info.Size() <= maxTotalSize*1024) {
+			kept++
+			totalSize += info.Size()
+			continue
+		}
+		path := filepath.Join(logDir, info.Name())
+		if err := os.Remove(path); err != nil {
+			_, _ = os.Stderr.WriteString("remove backup " + path + " failed,err:" + err.Error())
+			continue
+		}
+		if onDelete != nil {
+			onDelete(path)
+		}
+	}
+}
+
+// backupMatcher
+//
+//	@Description: 创建判断文件是否为当前日志的压缩文件的函数，每次清理时创建一次
+//	@receiver f
+//	@param layout 时间块的格式
+//	@return func(name string) bool
+func (f *FileSink) backupMatcher(layout string) func(name string) bool {
+	if f.nameTemplate == nil {
+		return func(name string) bool {
+			return isBackup(f.baseName, layout, name)
+		}
+	}
+	return f.nameTemplate.archiveMatcher(layout)
+}
+
+// isBackup
+//
+//	@Description: 判断文件是否为日志文件的压缩文件，即 日志文件名-时间块.zip、日志文件名-序号.zip 或 日志文件名-时间块.序号.zip
+//	@param baseName 日志文件名
+//	@param layout 时间块的格式
+//	@param name
+//	@return bool
+func isBackup(baseName, layout, name string) bool {
+	if !strings.HasPrefix(name, baseName+"-") || !strings.HasSuffix(name, ".zip") {
+		return false
+	}
+	block := name[len(baseName)+1 : len(name)-len(".zip")]
+	if isDigits(block) {
+		return true
+	}
+	if i := strings.LastIndexByte(block, '.'); i >= 0 && isDigits(block[i+1:]) {
+		block = block[:i]
+	}
+	if isDigits(block) {
+		return true
+	}
+	_, err := time.Parse(layout, block)
+	return err == nil
+}
+
+// backupIndex
+//
+//	@Description: 解析滚动文件或其压缩文件名中的序号
+//	@param prefix 序号前的文件名，如app.log-
+//	@param name 如app.log-3、app.log-3.zip
+//	@return int64
+//	@return bool 不是以prefix开头的滚动文件时返回false
+func backupIndex(prefix, name string) (int64, bool) {
+	if !strings.HasPrefix(name, prefix) {
+		return 0, false
+	}
+	block := strings.TrimSuffix(name[len(prefix):], ".zip")
+	if !isDigits(block) {
+		return 0, false
+	}
+	index, err := strconv.ParseInt(block, 10, 64)
+	return index, err == nil
+}
+
+// isDigits
+//
+//	@Description: 是否为非空的十进制数字
+//	@param s
+//	@return bool
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// getLogFile
+//
+//	@Description: 获取文件句柄
+//	@receiver f
+//	@return *os.File
+func (f *FileSink) getLogFile() *os.File {
+	if f.nameTemplate != nil {
+		return f.getTemplateFile()
+	}
+	fileInfo, err := os.Stat(f.logName)
+	if os.IsNotExist(err) { //文件不存在
+		if f.logFile != nil {
+			_ = f.logFile.Close()
+		}
+		file, err := os.Create(f.logName)
+		if err != nil {
+			_, _ = os.Stderr.WriteString("create logfile " + f.logName + " failed,err:" + err.Error())
+			f.logFile = nil
+			return nil
+		}
+		f.logFile = file
+		f.updateLink(f.logName)
+		return file
+	}
+	//  在同一个时间块但是还没打开，或者文件被外部工具移动后又创建了同名文件
+	if f.logFile == nil || f.fileChanged(f.logName, fileInfo) {
+		if f.logFile != nil {
+			_ = f.logFile.Close()
+			f.logFile = nil
+		}
+		if f.openFile(f.logName) == nil {
+			return nil
+		}
+	}
+	//  文件存在 根据时间滚动文件
+	if f.rollSchedule != nil {
+		return f.getFileByTime(fileInfo)
+	}
+	//  文件存在 根据文件的大小滚动文件
+	if f.rollLogBySize != 0 {
+		return f.getFileBySize(fileInfo)
+	}
+
+	return f.logFile
+}
+
+// getFileByTime
+//
+//	@Description: 按滚动计划滚动文件，同时设置了RollLogBySize时在同一时间块内超过大小也会滚动，滚动后的文件名为 日志文件名-时间块.序号
+//	@receiver f
+//	@param fileInfo
+//	@return *os.File
+func (f *FileSink) getFileByTime(fileInfo os.FileInfo) *os.File {
+	now := time.Now().In(f.rollLocation)
+	if f.blockStart.IsZero() {
+		f.alignBlock(fileInfo.ModTime())
+	}
+	if f.nextRoll.IsZero() {
+		f.nextRoll = f.rollSchedule.next(f.blockStart.In(f.rollLocation))
+	}
+	rolled := f.logName + "-" + f.blockStart.In(f.rollLocation).Format(f.rollLayout)
+	//  到达滚动时间
+	if !f.nextRoll.IsZero() && !now.Before(f.nextRoll) {
+		if f.rollLogBySize != 0 {
+			index, ok := f.nextBackupIndex(filepath.Base(rolled) + ".")
+			if !ok {
+				return nil
+			}
+			rolled += "." + strconv.FormatInt(index, 10)
+		}
+		file := f.rollFile(rolled)
+		if file != nil {
+			//  长时间没有日志时可能跨过多个时间块，新文件属于当前时间所在的时间块
+			f.alignBlock(now)
+		}
+		return file
+	}
+	//  同一时间块内文件大小超过滚动的大小
+	if f.rollLogBySize != 0 && f.rollLogBySize < fileInfo.Size()/1024 {
+		index, ok := f.nextBackupIndex(filepath.Base(rolled) + ".")
+		if !ok {
+			return nil
+		}
+		return f.rollFile(rolled + "." + strconv.FormatInt(index, 10))
+	}
+
+	return f.logFile
+}
+
+// getFileBySize
+//
+//	@Description: 根据文件大小滚动文件
+//	@receiver f
+//	@param fileInfo
+//	@return *os.File
+func (f *FileSink) getFileBySize(fileInfo os.FileInfo) *os.File {
+	sizeKB := fileInfo.Size() / 1024
+	// 文件大小超过滚动的大小了需要重命名滚动
+	if f.rollLogBySize < sizeKB {
+		index, ok := f.nextBackupIndex(filepath.Base(f.logName) + "-")
+		if !ok {
+			return nil
+		}
+		return f.rollFile(f.logName + "-" + strconv.FormatInt(index, 10))
+	}
+	return f.logFile
+}
+
+// rollFile
+//
+//	@Description: 关闭并重命名当前的日志文件，提交压缩后创建新的日志文件
+//	@receiver f
+//	@param rolled 重命名后的文件路径
+//	@return *os.File
+func (f *FileSink) rollFile(rolled string) *os.File {
+	// 如果文件被打开需要关闭
+	if f.logFile != nil {
+		_ = f.logFile.Close()
+		f.logFile = nil
+	}
+	err := os.Rename(f.logName, rolled)
+	if err != nil {
+		_, _ = os.Stderr.WriteString("Rename " + f.logName + " failed,err:" + err.Error())
+		return nil
+	}
+	f.compress(rolled)
+	file, err := os.Create(f.logName)
+	if err != nil {
+		_, _ = os.Stderr.WriteString("create logfile " + f.logName + " failed,err:" + err.Error())
+		return nil
+	}
+	f.logFile = file
+	f.updateLink(f.logName)
+	return file
+}
+
+// nextBackupIndex
+//
+//	@Description: 获取下一个滚动文件的序号，取已有的滚动文件（包括还未压缩完成的）的最大序号加一，旧的压缩文件被清理后序号也不会重复
+//	@receiver f
+//	@param prefix 序号前的文件名，如app.log-、app.log-202302281455.
+//	@return int64
+//	@return bool 读取目录失败时返回false
+func (f *FileSink) nextBackupIndex(prefix string) (int64, bool) {
+	entries, err := os.ReadDir(f.logDir)
+	if err != nil {
+		_, _ = os.Stderr.WriteString("ReadDir " + f.logDir + " failed,err:" + err.Error())
+		return 0, false
+	}
+	var last int64
+	for _, entry := range entries {
+		if index, ok := backupIndex(prefix, entry.Name()); ok && index > last {
+			last = index
+		}
+	}
+	return last + 1, true
+}
+
+// alignBlock
+//
+//	@Description: 将当前时间块设为t所在的时间块，开始时间对齐到滚动计划，不按时间滚动时为t
+//	@receiver f
+//	@param t
+func (f *FileSink) alignBlock(t time.Time) {
+	t = t.In(f.rollLocation)
+	f.blockStart = t
+	if f.rollSchedule != nil {
+		if start := f.rollSchedule.prev(t); !start.IsZero() {
+			f.blockStart = start
+		}
+	}
+	f.nextRoll = time.Time{}
+}
+
+// getTemplateFile
+//
+//	@Description: 使用模板时获取文件句柄，到达滚动时间或超过滚动大小时切换到按模板生成的新文件并压缩旧文件
+//	@receiver f
+//	@return *os.File
+func (f *FileSink) getTemplateFile() *os.File {
+	now := time.Now().In(f.rollLocation)
+	if f.logFile == nil {
+		//  Reopen后继续写入原文件
+		if f.activeName != "" {
+			return f.openFile(f.activeName)
+		}
+		f.alignBlock(now)
+		return f.openTemplateFile(true)
+	}
+	//  文件被外部工具移动或删除
+	if f.fileChanged(f.activeName, nil) {
+		_ = f.logFile.Close()
+		f.logFile = nil
+		return f.openFile(f.activeName)
+	}
+	if f.rollSchedule != nil {
+		if f.nextRoll.IsZero() {
+			f.nextRoll = f.rollSchedule.next(f.blockStart.In(f.rollLocation))
+		}
+		//  到达滚动时间
+		if !f.nextRoll.IsZero() && !now.Before(f.nextRoll) {
+			f.alignBlock(now)
+			return f.rollTemplateFile()
+		}
+	}
+	//  文件大小超过滚动的大小
+	if f.rollLogBySize != 0 && f.rollLogBySize < f.logFileSize/1024 {
+		return f.rollTemplateFile()
+	}
+	return f.logFile
+}
+
+// rollTemplateFile
+//
+//	@Description: 使用模板时关闭并压缩当前文件，打开新文件；新文件名与当前文件相同时继续写入当前文件
+//	@receiver f
+//	@return *os.File
+func (f *FileSink) rollTemplateFile() *os.File {
+	name, ok := f.templateName(false)
+	if !ok {
+		return nil
+	}
+	if name == f.activeName {
+		return f.logFile
+	}
+	_ = f.logFile.Close()
+	f.logFile = nil
+	if f.compressChan != nil {
+		f.compress(f.activeName)
+	}
+	return f.openFile(name)
+}
+
+// openTemplateFile
+//
+//	@Description: 使用模板时打开当前时间块的文件
+//	@receiver f
+//	@param resume 是否继续写入当前时间块序号最大且未压缩的文件
+//	@return *os.File
+func (f *FileSink) openTemplateFile(resume bool) *os.File {
+	name, ok := f.templateName(resume)
+	if !ok {
+		return nil
+	}
+	return f.openFile(name)
+}
+
+// templateName
+//
+//	@Description: 按模板生成当前时间块的文件路径，序号取已有文件（包括压缩文件）的最大序号加一
+//	@receiver f
+//	@param resume 为true且序号最大的文件未压缩时返回该文件
+//	@return string
+//	@return bool 读取目录失败时返回false
+func (f *FileSink) templateName(resume bool) (string, bool) {
+	block := f.blockStart.In(f.rollLocation)
+	var index int64
+	if f.nameTemplate.hasIndex {
+		entries, err := os.ReadDir(f.logDir)
+		if err != nil {
+			_, _ = os.Stderr.WriteString("ReadDir " + f.logDir + " failed,err:" + err.Error())
+			return "", false
+		}
+		pattern := f.nameTemplate.regexp(&block, f.rollLayout)
+		var last int64
+		var compressed bool
+		for _, entry := range entries {
+			match := pattern.FindStringSubmatch(entry.Name())
+			if match == nil {
+				continue
+			}
+			zip := match[pattern.SubexpIndex("zip")] != ""
+			if i, err := strconv.ParseInt(match[pattern.SubexpIndex("index")], 10, 64); err == nil && (i > last || (i == last && !zip)) {
+				last, compressed = i, zip
+			}
+		}
+		index = last + 1
+		if resume && last > 0 && !compressed {
+			index = last
+		}
+	}
+	return filepath.Join(f.logDir, f.nameTemplate.render(block, f.rollLayout, index)), true
+}
+
+// openFile
+//
+//	@Description: 以追加方式打开日志文件作为当前文件，并更新符号链接
+//	@receiver f
+//	@param name
+//	@return *os.File
+func (f *FileSink) openFile(name string) *os.File {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		_, _ = os.Stderr.WriteString("open logfile " + name + " failed,err:" + err.Error())
+		return nil
+	}
+	f.logFileSize = 0
+	if info, err := file.Stat(); err == nil {
+		f.logFileSize = info.Size()
+	}
+	f.logFile = file
+	f.activeName = name
+	f.lastCheck = time.Now()
+	f.updateLink(name)
+	return file
+}
+
+// fileChanged
+//
+//	@Description: 判断打开的文件是否已不是name对应的文件，如被移动、删除或替换；每fileCheckInterval最多检查一次
+//	@receiver f
+//	@param name 文件路径
+//	@param info name的文件信息，为nil时重新获取
+//	@return bool
+func (f *FileSink) fileChanged(name string, info os.FileInfo) bool {
+	now := time.Now()
+	if now.Sub(f.lastCheck) < fileCheckInterval {
+		return false
+	}
+	f.lastCheck = now
+	if info == nil {
+		var err error
+		if info, err = os.Stat(name); err != nil {
+			return true
+		}
+	}
+	opened, err := f.logFile.Stat()
+	return err != nil || !os.SameFile(info, opened)
+}
+
+// updateLink
+//
+//	@Description: 将符号链接指向当前日志文件，先创建临时链接再重命名，保证链接始终存在
+//	@receiver f
+//	@param target 当前日志文件路径
+func (f *FileSink) updateLink(target string) {
+	if f.currentLink == "" {
+		return
+	}
+	link := filepath.Join(f.logDir, f.currentLink)
+	dest := filepath.Base(target)
+	if current, err := os.Readlink(link); err == nil && current == dest {
+		return
+	}
+	tmp := link + ".tmp"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(dest, tmp); err != nil {
+		_, _ = os.Stderr.WriteString("create symlink " + link + " failed,err:" + err.Error())
+		return
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		_, _ = os.Stderr.WriteString("create symlink " + link + " failed,err:" + err.Error())
+		_ = os.Remove(tmp)
+	}
+}
+
+// validateCurrentLink
+//
+//	@Description: 校验符号链接名
+//	@param link
+//	@param logName
+//	@param template 是否使用模板，不使用模板时链接名不能与日志文件名相同
+//	@return error
+func validateCurrentLink(link, logName string, template bool) error {
+	if link == "" {
+		return nil
+	}
+	if link != filepath.Base(link) || link == "." || link == ".." {
+		return errors.New("invalid current_link " + strconv.Quote(link) + ", must be a file name in the log directory")
+	}
+	if !template && link == filepath.Base(logName) {
+		return errors.New("current_link " + strconv.Quote(link) + " conflicts with the log file")
+	}
+	return nil
+}
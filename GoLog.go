@@ -1,54 +1,118 @@
 package go_log
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"runtime"
 	"strconv"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 )
 
 // GoLogConfig
-// @Description:GoLog 配置类，当RollLogByTime、RollLogBySize二者都不为空时只会生效一个，优选使用RollLogByTime
+// @Description:GoLog 配置类，RollLogByTime、RollLogBySize可以同时设置，此时每个时间块滚动为 LogName-时间块.1、.2...，时间块内超过大小时拆分出下一个序号
 type GoLogConfig struct {
-	LogLevel       LogLevel    `json:"log_level"`        //日志级别
-	ShortLogEnable bool        `json:"short_log_enable"` //是否使用短日志
-	MsgChan        chan string `json:"msg_chan"`         //消息管道（缓冲区）
-	Writer         io.Writer   `json:"-"`                //输出流 可以使用文件、网络
-	ConsoleEnable  bool        `json:"console_enable"`   //控制台输出
-	ColorEnable    bool        `json:"color_enable"`     //颜色输出
-	LogDir         string      `json:"log_dir"`          //日志存放目录
-	LogName        string      `json:"log_name"`         //日志文件名
-	RollLogByTime  string      `json:"roll_log_by_time"` //根据时间滚动 如:5m表示五分钟滚动一个，为了便于管理这里会把时间整块分，如16:56:23则会写进16:55:00这个时间块的文件中
-	RollLogBySize  int64       `json:"roll_log_by_size"` //根据文件大小滚动，单位KB，
+	LogLevel         LogLevel            `json:"log_level"`          //日志级别
+	ShortLogEnable   bool                `json:"short_log_enable"`   //是否使用短日志
+	MsgChan          chan string         `json:"-"`                  //已废弃，不为空时只使用其容量作为缓冲区长度
+	BufferSize       int                 `json:"buffer_size"`        //缓冲区长度，MsgChan为空时生效，为0时使用DefaultBufferSize
+	OverflowPolicy   OverflowPolicy      `json:"overflow_policy"`    //管道写满时的处理策略，为空时阻塞 @See OverflowPolicy
+	BlockTimeout     string              `json:"block_timeout"`      //block_timeout策略的超时时间，如100ms，为空时使用DefaultBlockTimeout
+	Writer           io.Writer           `json:"-"`                  //输出流 可以使用文件、网络，不输出颜色
+	WriterLogLevel   LogLevel            `json:"-"`                  //输出流的最低日志级别，为空时与LogLevel相同
+	ConsoleEnable    bool                `json:"console_enable"`     //控制台输出
+	ColorEnable      bool                `json:"color_enable"`       //颜色输出，只作用于控制台
+	ConsoleLogLevel  LogLevel            `json:"console_log_level"`  //控制台的最低日志级别，为空时与LogLevel相同
+	LogDir           string              `json:"log_dir"`            //日志存放目录
+	LogName          string              `json:"log_name"`           //日志文件名，日志文件不输出颜色
+	FileLogLevel     LogLevel            `json:"file_log_level"`     //日志文件的最低日志级别，为空时与LogLevel相同
+	RollLogByTime    string              `json:"roll_log_by_time"`   //根据时间滚动 如:5m表示五分钟滚动一个，为了便于管理这里会把时间整块分，如16:56:23则会写进16:55:00这个时间块的文件中，时间块以RollLocation的零点对齐；也可以是hourly、daily、weekly、monthly或cron表达式如"0 3 * * *"
+	RollLocation     string              `json:"roll_location"`      //滚动时间使用的时区，如Asia/Shanghai，为空时使用本地时区
+	RollTimeLayout   string              `json:"roll_time_layout"`   //滚动文件名中时间块的格式，如20060102，为空时使用DateTimeLayout4
+	RollNameTemplate string              `json:"roll_name_template"` //滚动文件名模板，如{dir}/{name}-{time:20060102-1504}.{index}{ext}，设置后日志直接写入按模板生成的文件，滚动时切换到新文件并压缩旧文件；包含{time}时需要设置RollLogByTime，设置RollLogBySize时需要包含{index}；为空时写入LogName，滚动时重命名为LogName-时间块或LogName-序号
+	CurrentLink      string              `json:"current_link"`       //指向当前日志文件的符号链接名，位于LogDir下，如current，为空时不创建；使用模板时可以与LogName相同
+	ReopenOnSighup   bool                `json:"reopen_on_sighup"`   //收到SIGHUP信号时调用Reopen，便于配合logrotate；开启后SIGHUP不再终止进程，windows下不生效
+	RollLogBySize    int64               `json:"roll_log_by_size"`   //根据文件大小滚动，单位KB，
+	MaxBackups       int                 `json:"max_backups"`        //最多保留的压缩文件个数，超出时删除最旧的，0表示不限制
+	MaxAge           string              `json:"max_age"`            //压缩文件的最长保留时间，如168h，为空表示不限制
+	MaxTotalSize     int64               `json:"max_total_size"`     //压缩文件的总大小上限，单位KB，超出时删除最旧的，0表示不限制
+	OnDelete         func(path string)   `json:"-"`                  //保留策略删除压缩文件后的回调，可用于审计；在压缩协程中调用，可以通过同一个日志输出
+	LogFormat        LogFormat           `json:"log_format"`         //日志输出格式，默认为text
+	JsonFormat       *JsonFormatConfig   `json:"json_format"`        //LogFormat为json时的格式化配置，为空使用默认配置
+	LogfmtFormat     *LogfmtFormatConfig `json:"logfmt_format"`      //LogFormat为logfmt时的格式化配置，为空使用默认配置
+	Pattern          string              `json:"pattern"`            //LogFormat为text时使用的转换模式，为空使用默认的列格式 @See CompilePattern
+	VModule          string              `json:"vmodule"`            //按调用者文件或包路径覆盖日志级别，如"rotation*=DEBUG,github.com/foo/bar/*=TRACE" @See SetVModule
+	Sinks            []Sink              `json:"-"`                  //额外的输出端 @See AddSink
+}
 
+// flushRequest
+// @Description: 刷新请求，消费协程写出管道中已有的日志后执行fn，并将刷盘的结果写入done
+type flushRequest struct {
+	done chan error //完成信号，容量为1
+	sync bool       //是否将输出端刷入磁盘
+	fn   func()     //需要在消费协程中执行的操作，如修改日志文件相关的状态
 }
 
 // GoLog
 // @Description: GoLog 实体类
 type GoLog struct {
-	sync.RWMutex
+	*goLogCore        //日志核心，通过With派生的子日志与父日志共享同一个核心
+	fields     Fields //绑定在该日志上的字段，会附加到每一条日志中
+	parent     *GoLog //父日志，根日志为nil
+}
+
+// goLogCore
+// @Description: 日志核心，包含管道、输出端等共享的配置与状态
+type goLogCore struct {
+	sync.RWMutex                              //修改配置快照时加写锁，保证并发的修改不会相互覆盖
+	config          atomic.Pointer[logConfig] //配置快照，读取时不加锁
+	msgChan         chan *LogEntity           //消息管道（缓冲区）
+	consoleSink     *WriterSink               //控制台输出端
+	writerSink      *WriterSink               //Writer输出端，未设置Writer时为nil
+	fileSink        *FileSink                 //日志文件输出端，未设置LogDir、LogName时为nil
+	sinks           []Sink                    //除控制台外的全部输出端，只在消费协程中访问
+	waiter          sync.WaitGroup            //阻塞
+	writeLock       sync.Mutex                //输出端写入锁，sync_write策略下调用方协程与消费协程互斥
+	dropped         atomic.Uint64             //丢弃的日志总数
+	reportedDropped uint64                    //已输出过的丢弃数量，只在消费协程中访问
+	lastDropReport  time.Time                 //上一次输出丢弃数量的时间，只在消费协程中访问
+	flushChan       chan flushRequest         //刷新信号管道
+	state           atomic.Int32              //生命周期状态 @See stateRunning
+	inflight        atomic.Int64              //正在写入管道或向消费协程发送请求的调用方数量
+	idle            chan struct{}             //关闭开始后inflight降为0时发出信号
+	closed          chan struct{}             //关闭完成信号
+	closeErr        error                     //关闭输出端时第一个失败的错误，closed关闭后可读
+}
+
+// logConfig
+// @Description: 运行时配置快照，创建后不再修改，修改配置时复制一份修改后整体替换
+type logConfig struct {
 	logLevel       LogLevel                      //日志级别
 	shortLogEnable bool                          //是否使用短日志
-	msgChan        chan string                   //消息管道（缓冲区）
-	writer         io.Writer                     //输出流
 	consoleEnable  bool                          //控制台输出
-	colorEnable    bool                          //颜色输出
-	waiter         sync.WaitGroup                //阻塞
 	logFormatter   func(entry *LogEntity) string //格式化器
-	logDir         string                        //日志存放目录
-	logName        string                        //日志文件名
-	rollLogByTime  time.Duration                 //根据时间滚动 如:5m表示五分钟滚动一个，为了便于管理这里会把时间整块分，如16:56:23则会写进16:55:00这个时间块的文件中
-	rollLogBySize  int64                         //根据文件大小滚动，单位KB，
-	logFile        *os.File                      //日志文件句柄
-	lastTimeBlock  string                        //文件最后变更时间的时间块
-	logFileSize    int64                         //当前日志文件的大小
-	compressChan   chan string                   //压缩文件信号管道，将要压缩的文件名丢入管道
-	closeFlag      bool
+	pattern        *PatternLayout                //转换模式
+	vmodule        *vmodule                      //按文件/包覆盖日志级别
+	overflowPolicy OverflowPolicy                //管道写满时的处理策略
+	blockTimeout   time.Duration                 //block_timeout策略的超时时间
+}
+
+// DefaultConfig
+//
+//	@Description: 获取默认配置：Info级别、短日志、控制台彩色输出、缓冲区长度256
+//	@return *GoLogConfig
+func DefaultConfig() *GoLogConfig {
+	return &GoLogConfig{
+		LogLevel:       LoglevelInfo,
+		ShortLogEnable: true,
+		BufferSize:     256,
+		ConsoleEnable:  true,
+		ColorEnable:    true,
+	}
 }
 
 // DefaultGoLog
@@ -58,17 +122,7 @@ type GoLog struct {
 //	@Data 2023-02-27 14:25:54
 //	@return *GoLog
 func DefaultGoLog() *GoLog {
-	g := &GoLog{
-		RWMutex:        sync.RWMutex{},
-		logLevel:       LoglevelInfo,
-		shortLogEnable: true,
-		msgChan:        make(chan string, 256),
-		writer:         nil,
-		consoleEnable:  true,
-		colorEnable:    true,
-		waiter:         sync.WaitGroup{},
-	}
-	go g.consumeMsgChan()
+	g, _ := newGoLog(DefaultConfig())
 	return g
 }
 
@@ -90,243 +144,738 @@ func GetSingleGoLog() *GoLog {
 
 // NewGoLog
 //
-//	@Description: 创建日志
+//	@Description: 创建日志，配置不合法时panic，需要返回错误时使用GoLogConfig.Build
 //	@param config
 //	@return *GoLog
 func NewGoLog(config *GoLogConfig) ILogger {
+	g, err := newGoLog(config)
+	if err != nil {
+		panic(err.Error())
+	}
+	return g
+}
+
+// Build
+//
+//	@Description: 校验配置并创建日志
+//	@receiver config
+//	@return *GoLog
+//	@return error 配置不合法
+func (config *GoLogConfig) Build() (*GoLog, error) {
+	return newGoLog(config)
+}
 
-	g := &GoLog{
-		RWMutex:        sync.RWMutex{},
-		logLevel:       config.LogLevel,
-		shortLogEnable: config.ShortLogEnable,
-		msgChan:        config.MsgChan,
-		writer:         nil,
-		consoleEnable:  config.ConsoleEnable,
-		colorEnable:    config.ColorEnable,
-		waiter:         sync.WaitGroup{},
-		logDir:         config.LogDir,
-		logName:        config.LogName,
-		rollLogBySize:  config.RollLogBySize,
-	}
-	if config.RollLogByTime != "" {
-		duration, err := time.ParseDuration(config.RollLogByTime)
+// newGoLog
+//
+//	@Description: 创建日志
+//	@param config
+//	@return *GoLog
+//	@return error 配置不合法
+func newGoLog(config *GoLogConfig) (*GoLog, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	bufferSize := config.BufferSize
+	if config.MsgChan != nil {
+		bufferSize = cap(config.MsgChan)
+	} else if bufferSize == 0 {
+		bufferSize = DefaultBufferSize
+	}
+	g := &GoLog{goLogCore: &goLogCore{
+		RWMutex:   sync.RWMutex{},
+		msgChan:   make(chan *LogEntity, bufferSize),
+		waiter:    sync.WaitGroup{},
+		flushChan: make(chan flushRequest),
+		closed:    make(chan struct{}),
+		idle:      make(chan struct{}, 1),
+	}}
+	g.config.Store(config.newLogConfig())
+	g.consoleSink = NewConsoleSink(SinkConfig{Level: config.ConsoleLogLevel, ColorEnable: config.ColorEnable})
+	g.consoleSink.inheritFormat(g.format)
+	if config.Writer != nil {
+		g.writerSink = NewWriterSink(config.Writer, SinkConfig{Level: config.WriterLogLevel})
+		g.addSink(g.writerSink)
+	}
+	if config.LogDir != "" && config.LogName != "" {
+		fileSink, err := NewFileSink(FileSinkConfig{
+			SinkConfig:     SinkConfig{Level: config.FileLogLevel},
+			LogDir:         config.LogDir,
+			LogName:        config.LogName,
+			RollLogByTime:  config.RollLogByTime,
+			RollLogBySize:  config.RollLogBySize,
+			RollLocation:   config.RollLocation,
+			RollTimeLayout: config.RollTimeLayout,
+			NameTemplate:   config.RollNameTemplate,
+			CurrentLink:    config.CurrentLink,
+			MaxBackups:     config.MaxBackups,
+			MaxAge:         config.MaxAge,
+			MaxTotalSize:   config.MaxTotalSize,
+			OnDelete:       config.OnDelete,
+		})
 		if err != nil {
-			panic("Invalid time:" + config.RollLogByTime)
+			return nil, err
 		}
-		g.rollLogByTime = duration
+		g.fileSink = fileSink
+		g.addSink(fileSink)
 	}
+	for _, sink := range config.Sinks {
+		g.addSink(sink)
+	}
+	g.waiter.Add(1)
 	go g.consumeMsgChan()
-	return g
+	if config.ReopenOnSighup {
+		g.reopenOnSignal()
+	}
+	return g, nil
 }
+
 func (g *GoLog) Trace(format string, msg ...any) {
-	if g.logLevel.LevelNum() > LoglevelTrace.LevelNum() || g.closeFlag {
-		return
+	g.logf(LoglevelTrace, format, msg)
+}
+
+func (g *GoLog) Debug(format string, msg ...any) {
+	g.logf(LoglevelDebug, format, msg)
+}
+
+func (g *GoLog) Info(format string, msg ...any) {
+	g.logf(LoglevelInfo, format, msg)
+}
+
+func (g *GoLog) Warn(format string, msg ...interface{}) {
+	g.logf(LoglevelWarn, format, msg)
+}
+
+func (g *GoLog) Error(format string, msg ...interface{}) {
+	g.logf(LoglevelError, format, msg)
+}
+
+// Log
+//
+//	@Description: 指定级别的日志，级别为PANIC、FATAL时与Panic、Fatal的行为一致，未注册的级别不会输出
+//	@receiver g
+//	@param level
+//	@param format
+//	@param msg
+func (g *GoLog) Log(level LogLevel, format string, msg ...any) {
+	g.logf(level, format, msg)
+	switch level {
+	case LoglevelPanic:
+		g.flush(true)
+		panic(fmt.Sprintf(format, msg...))
+	case LoglevelFatal:
+		g.exit()
 	}
-	if _, file, line, ok := runtime.Caller(1); ok {
-		data := fmt.Sprintf(format, msg...)
-		entity := LogEntity{
-			LogTime:  time.Now(),
-			LogLevel: LoglevelTrace,
-			LogFile:  g.fileIdx(file),
-			LineNum:  line,
-			Msg:      data,
-		}
-		if g.logFormatter != nil {
-			g.msgChan <- g.logFormatter(&entity)
-		} else {
-			g.msgChan <- g.formatMsg(&entity)
-		}
+}
+
+// Panic
+//
+//	@Description: Panic级别日志，日志写出并刷盘后以日志内容panic
+//	@receiver g
+//	@param format
+//	@param msg
+func (g *GoLog) Panic(format string, msg ...any) {
+	g.logf(LoglevelPanic, format, msg)
+	g.flush(true)
+	panic(fmt.Sprintf(format, msg...))
+}
+
+// Fatal
+//
+//	@Description: Fatal级别日志，写出管道中的全部日志、关闭日志文件、等待压缩完成、执行退出钩子后以状态码1退出进程
+//	@receiver g
+//	@param format
+//	@param msg
+func (g *GoLog) Fatal(format string, msg ...any) {
+	g.logf(LoglevelFatal, format, msg)
+	g.exit()
+}
+
+func (g *GoLog) Tracew(msg string, keysAndValues ...any) {
+	g.logw(LoglevelTrace, msg, keysAndValues)
+}
+
+func (g *GoLog) Debugw(msg string, keysAndValues ...any) {
+	g.logw(LoglevelDebug, msg, keysAndValues)
+}
+
+func (g *GoLog) Infow(msg string, keysAndValues ...any) {
+	g.logw(LoglevelInfo, msg, keysAndValues)
+}
+
+func (g *GoLog) Warnw(msg string, keysAndValues ...any) {
+	g.logw(LoglevelWarn, msg, keysAndValues)
+}
+
+func (g *GoLog) Errorw(msg string, keysAndValues ...any) {
+	g.logw(LoglevelError, msg, keysAndValues)
+}
+
+func (g *GoLog) Panicw(msg string, keysAndValues ...any) {
+	g.logw(LoglevelPanic, msg, keysAndValues)
+	g.flush(true)
+	panic(msg)
+}
+
+func (g *GoLog) Fatalw(msg string, keysAndValues ...any) {
+	g.logw(LoglevelFatal, msg, keysAndValues)
+	g.exit()
+}
+
+func (g *GoLog) TraceCtx(ctx context.Context, format string, msg ...any) {
+	g.logCtx(ctx, LoglevelTrace, format, msg)
+}
+
+func (g *GoLog) DebugCtx(ctx context.Context, format string, msg ...any) {
+	g.logCtx(ctx, LoglevelDebug, format, msg)
+}
+
+func (g *GoLog) InfoCtx(ctx context.Context, format string, msg ...any) {
+	g.logCtx(ctx, LoglevelInfo, format, msg)
+}
+
+func (g *GoLog) WarnCtx(ctx context.Context, format string, msg ...any) {
+	g.logCtx(ctx, LoglevelWarn, format, msg)
+}
+
+func (g *GoLog) ErrorCtx(ctx context.Context, format string, msg ...any) {
+	g.logCtx(ctx, LoglevelError, format, msg)
+}
+
+func (g *GoLog) PanicCtx(ctx context.Context, format string, msg ...any) {
+	g.logCtx(ctx, LoglevelPanic, format, msg)
+	g.flush(true)
+	panic(fmt.Sprintf(format, msg...))
+}
+
+func (g *GoLog) FatalCtx(ctx context.Context, format string, msg ...any) {
+	g.logCtx(ctx, LoglevelFatal, format, msg)
+	g.exit()
+}
+
+// With
+//
+//	@Description: 派生一个绑定了字段的子日志，子日志与父日志共享管道、输出流、滚动和日志级别，
+//	绑定的字段会附加到子日志输出的每一条日志中；对子日志调用Destroy不会销毁父日志
+//	@receiver g
+//	@param keysAndValues 交替出现的key、value
+//	@return ILogger
+func (g *GoLog) With(keysAndValues ...any) ILogger {
+	return g.with(toFields(keysAndValues))
+}
+
+// with
+//
+//	@Description: 派生一个绑定了字段的子日志
+//	@receiver g
+//	@param fields
+//	@return *GoLog
+func (g *GoLog) with(fields Fields) *GoLog {
+	bound := make(Fields, 0, len(g.fields)+len(fields))
+	bound = append(bound, g.fields...)
+	bound = append(bound, freezeFields(fields)...)
+	return &GoLog{goLogCore: g.goLogCore, fields: bound, parent: g}
+}
+
+// callerDepth 从runtime.Callers到业务调用方的栈深度：业务代码 -> Info -> logf -> output -> runtime.Callers
+const callerDepth = 4
+
+// enabledDepth 从runtime.Callers到业务调用方的栈深度：业务代码 -> Info -> logf -> enabled -> runtime.Callers
+const enabledDepth = 4
+
+// update
+//
+//	@Description: 复制当前的配置快照，修改后整体替换，并发的修改串行执行
+//	@receiver g
+//	@param fn
+func (g *GoLog) update(fn func(c *logConfig)) {
+	g.Lock()
+	defer g.Unlock()
+	c := *g.config.Load()
+	fn(&c)
+	g.config.Store(&c)
+}
+
+// enabled
+//
+//	@Description: 判断指定级别的日志是否需要输出，配置了vmodule时按业务调用方所在的文件判断
+//	@receiver g
+//	@param level
+//	@return bool
+func (g *GoLog) enabled(level LogLevel) bool {
+	//  未注册的级别
+	if level.LevelNum() < 0 {
+		return false
+	}
+	c := g.config.Load()
+	if c.vmodule == nil {
+		return c.logLevel.LevelNum() <= level.LevelNum()
+	}
+	var pcs [1]uintptr
+	if runtime.Callers(enabledDepth, pcs[:]) == 0 {
+		return c.logLevel.LevelNum() <= level.LevelNum()
 	}
+	return c.vmodule.threshold(pcs[0], c.logLevel.LevelNum()) <= level.LevelNum()
 }
 
-func (g *GoLog) Debug(format string, msg ...any) {
-	if g.logLevel.LevelNum() > LoglevelDebug.LevelNum() || g.closeFlag {
-		return
+// enabledAt
+//
+//	@Description: 判断指定调用点、指定级别的日志是否需要输出
+//	@receiver g
+//	@param level
+//	@param pc 调用点，为0时只按日志级别判断
+//	@return bool
+func (g *GoLog) enabledAt(level LogLevel, pc uintptr) bool {
+	if level.LevelNum() < 0 {
+		return false
 	}
-	if _, file, line, ok := runtime.Caller(1); ok {
-		data := fmt.Sprintf(format, msg...)
-		entity := LogEntity{
-			LogTime:  time.Now(),
-			LogLevel: LoglevelDebug,
-			LogFile:  g.fileIdx(file),
-			LineNum:  line,
-			Msg:      data,
-		}
-		if g.logFormatter != nil {
-			g.msgChan <- g.logFormatter(&entity)
-		} else {
-			g.msgChan <- g.formatMsg(&entity)
-		}
+	c := g.config.Load()
+	if c.vmodule != nil && pc != 0 {
+		return c.vmodule.threshold(pc, c.logLevel.LevelNum()) <= level.LevelNum()
 	}
+	return c.logLevel.LevelNum() <= level.LevelNum()
 }
 
-func (g *GoLog) Info(format string, msg ...any) {
-	if g.logLevel.LevelNum() > LoglevelInfo.LevelNum() || g.closeFlag {
+// mayEnabled
+//
+//	@Description: 在不知道调用点时判断指定级别的日志是否可能需要输出，配置了vmodule时取所有规则与日志级别中最低的
+//	@receiver g
+//	@param level
+//	@return bool
+func (g *GoLog) mayEnabled(level LogLevel) bool {
+	if level.LevelNum() < 0 {
+		return false
+	}
+	c := g.config.Load()
+	threshold := c.logLevel.LevelNum()
+	if c.vmodule != nil && c.vmodule.min < threshold {
+		threshold = c.vmodule.min
+	}
+	return threshold <= level.LevelNum()
+}
+
+// logf
+//
+//	@Description: 格式化字符串形式的日志
+//	@receiver g
+//	@param level
+//	@param format
+//	@param msg
+func (g *GoLog) logf(level LogLevel, format string, msg []any) {
+	if !g.enabled(level) {
 		return
 	}
-	if _, file, line, ok := runtime.Caller(1); ok {
-		data := fmt.Sprintf(format, msg...)
-		entity := LogEntity{
-			LogTime:  time.Now(),
-			LogLevel: LoglevelInfo,
-			LogFile:  g.fileIdx(file),
-			LineNum:  line,
-			Msg:      data,
-		}
-		if g.logFormatter != nil {
-			g.msgChan <- g.logFormatter(&entity)
-		} else {
-			g.msgChan <- g.formatMsg(&entity)
-		}
+	g.outputf(level, format, msg, nil)
+}
+
+// logw
+//
+//	@Description: 带有key/value字段的日志
+//	@receiver g
+//	@param level
+//	@param msg
+//	@param keysAndValues 交替出现的key、value
+func (g *GoLog) logw(level LogLevel, msg string, keysAndValues []any) {
+	if !g.enabled(level) {
+		return
 	}
+	g.output(level, msg, toFields(keysAndValues))
 }
 
-func (g *GoLog) Warn(format string, msg ...interface{}) {
-	if g.logLevel.LevelNum() > LoglevelWarn.LevelNum() || g.closeFlag {
+// logCtx
+//
+//	@Description: 格式化字符串形式的日志，并附加从context中提取的字段
+//	@receiver g
+//	@param ctx
+//	@param level
+//	@param format
+//	@param msg
+func (g *GoLog) logCtx(ctx context.Context, level LogLevel, format string, msg []any) {
+	if !g.enabled(level) {
 		return
 	}
-	if _, file, line, ok := runtime.Caller(1); ok {
-		data := fmt.Sprintf(format, msg...)
-		entity := LogEntity{
-			LogTime:  time.Now(),
-			LogLevel: LoglevelWarn,
-			LogFile:  g.fileIdx(file),
-			LineNum:  line,
-			Msg:      data,
-		}
-		if g.logFormatter != nil {
-			g.msgChan <- g.logFormatter(&entity)
-		} else {
-			g.msgChan <- g.formatMsg(&entity)
+	g.outputf(level, format, msg, extractContextFields(ctx))
+}
+
+// outputf
+//
+//	@Description: 生成日志消息体写入消息管道，参数都是不可变的基础类型时在消费协程中格式化，否则立即格式化
+//	@receiver g
+//	@param level
+//	@param format
+//	@param msg
+//	@param fields
+func (g *GoLog) outputf(level LogLevel, format string, msg []any, fields Fields) {
+	var pcs [1]uintptr
+	runtime.Callers(callerDepth, pcs[:])
+	entity := newEntity(level, g.bindFields(freezeFields(fields)), pcs[0])
+	if immutableArgs(msg) {
+		entity.format = format
+		entity.args = append(entity.args[:0], msg...)
+		entity.lazy = true
+	} else {
+		entity.Msg = fmt.Sprintf(format, msg...)
+	}
+	g.submit(entity)
+}
+
+// output
+//
+//	@Description: 生成日志消息体写入消息管道
+//	@receiver g
+//	@param level
+//	@param msg
+//	@param fields
+func (g *GoLog) output(level LogLevel, msg string, fields Fields) {
+	var pcs [1]uintptr
+	runtime.Callers(callerDepth, pcs[:])
+	entity := newEntity(level, g.bindFields(freezeFields(fields)), pcs[0])
+	entity.Msg = msg
+	g.submit(entity)
+}
+
+// entityPool 日志消息体对象池
+var entityPool = sync.Pool{New: func() any {
+	return &LogEntity{}
+}}
+
+// newEntity
+//
+//	@Description: 从对象池中获取日志消息体，调用者的文件、行号在消费协程中解析
+//	@param level
+//	@param fields
+//	@param pc 调用点，为0时不输出文件、行号
+//	@return *LogEntity
+func newEntity(level LogLevel, fields Fields, pc uintptr) *LogEntity {
+	entity := entityPool.Get().(*LogEntity)
+	entity.LogTime = time.Now()
+	entity.LogLevel = level
+	entity.Fields = fields
+	entity.pc = pc
+	return entity
+}
+
+// releaseEntity
+//
+//	@Description: 清空日志消息体并放回对象池
+//	@param entity
+func releaseEntity(entity *LogEntity) {
+	args := entity.args
+	for i := range args {
+		args[i] = nil
+	}
+	*entity = LogEntity{args: args[:0]}
+	entityPool.Put(entity)
+}
+
+// immutableArgs
+//
+//	@Description: 判断参数是否都是不可变的基础类型，只有这样才能在消费协程中延迟格式化而不受调用方后续修改的影响
+//	@param args
+//	@return bool
+func immutableArgs(args []any) bool {
+	for _, arg := range args {
+		if !immutableValue(arg) {
+			return false
 		}
 	}
+	return true
 }
 
-func (g *GoLog) Error(format string, msg ...interface{}) {
-	if g.logLevel.LevelNum() > LoglevelError.LevelNum() || g.closeFlag {
-		return
+// immutableValue
+//
+//	@Description: 判断值是否为不可变的基础类型
+//	@param value
+//	@return bool
+func immutableValue(value any) bool {
+	switch value.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr,
+		float32, float64, complex64, complex128, time.Duration, time.Time:
+		return true
+	default:
+		return false
 	}
-	if _, file, line, ok := runtime.Caller(1); ok {
-		data := fmt.Sprintf(format, msg...)
-		entity := LogEntity{
-			LogTime:  time.Now(),
-			LogLevel: LoglevelError,
-			LogFile:  g.fileIdx(file),
-			LineNum:  line,
-			Msg:      data,
+}
+
+// freezeFields
+//
+//	@Description: 在调用方协程中为可变的字段值（map、切片、指针、error、Stringer等）生成快照，
+//	避免消费协程读取时与调用方的后续修改产生数据竞争；需要转换时返回副本，不修改传入的字段 @See freezeValue
+//	@param fields
+//	@return Fields
+func freezeFields(fields Fields) Fields {
+	for i, field := range fields {
+		if immutableValue(field.Value) {
+			continue
 		}
-		if g.logFormatter != nil {
-			g.msgChan <- g.logFormatter(&entity)
-		} else {
-			g.msgChan <- g.formatMsg(&entity)
+		frozen := make(Fields, len(fields))
+		copy(frozen, fields)
+		for j := i; j < len(frozen); j++ {
+			if !immutableValue(frozen[j].Value) {
+				frozen[j].Value = freezeValue(frozen[j].Value)
+			}
 		}
+		return frozen
 	}
+	return fields
+}
+
+// resolve
+//
+//	@Description: 在消费协程中格式化日志内容、解析调用者的文件、行号及函数名
+//	@receiver g
+//	@param entity
+func (g *GoLog) resolve(entity *LogEntity) {
+	if entity.lazy {
+		entity.Msg = fmt.Sprintf(entity.format, entity.args...)
+		entity.lazy = false
+	}
+	if entity.pc != 0 && entity.LogFile == "" {
+		frame, _ := runtime.CallersFrames([]uintptr{entity.pc}).Next()
+		entity.LogFile = g.fileIdx(frame.File)
+		entity.LineNum = frame.Line
+		entity.function = frame.Function
+	}
+}
+
+// bindFields
+//
+//	@Description: 在字段前附加上该日志绑定的字段
+//	@receiver g
+//	@param fields
+//	@return Fields
+func (g *GoLog) bindFields(fields Fields) Fields {
+	if len(g.fields) == 0 {
+		return fields
+	}
+	return append(g.fields[:len(g.fields):len(g.fields)], fields...)
+}
+
+// format
+//
+//	@Description: 按日志的格式化器、转换模式或默认的列格式格式化，作为未设置格式化器的输出端的格式
+//	@receiver g
+//	@param entry
+//	@param color 是否输出颜色
+//	@return string
+func (g *GoLog) format(entry *LogEntity, color bool) string {
+	c := g.config.Load()
+	if c.logFormatter != nil {
+		return c.logFormatter(entry)
+	} else if c.pattern != nil {
+		return c.pattern.Format(entry, color)
+	}
+	return formatMsg(entry, color)
 }
 
 func (g *GoLog) SetLogLevel(loglevel LogLevel) {
-	g.RLock()
-	defer g.RUnlock()
-	g.logLevel = loglevel
+	g.update(func(c *logConfig) {
+		c.logLevel = loglevel
+	})
 }
 
+// SetLohWriter
+//
+//	@Description: 设置输出流，输出流不输出颜色，为空时不再输出
+//	@receiver g
+//	@param writer
 func (g *GoLog) SetLohWriter(writer io.Writer) {
-	g.RLock()
-	defer g.RUnlock()
-	g.writer = writer
+	g.exec(func() {
+		if g.writerSink == nil {
+			g.writerSink = NewWriterSink(writer, SinkConfig{})
+			g.addSink(g.writerSink)
+			return
+		}
+		g.writerSink.SetWriter(writer)
+	})
+}
+
+// AddSink
+//
+//	@Description: 添加输出端，输出端未设置格式化器时使用该日志的格式；日志销毁时会关闭输出端
+//	@receiver g
+//	@param sink
+func (g *GoLog) AddSink(sink Sink) {
+	g.exec(func() {
+		g.addSink(sink)
+	})
+}
+
+// addSink
+//
+//	@Description: 添加输出端，只能在消费协程中或消费协程启动前调用
+//	@receiver g
+//	@param sink
+func (g *GoLog) addSink(sink Sink) {
+	if s, ok := sink.(interface {
+		inheritFormat(format func(entry *LogEntity, color bool) string)
+	}); ok {
+		s.inheritFormat(g.format)
+	}
+	g.sinks = append(g.sinks, sink)
+}
+
+// ConsoleSink
+//
+//	@Description: 获取控制台输出端，可用于设置控制台的日志级别、格式化器
+//	@receiver g
+//	@return *WriterSink
+func (g *GoLog) ConsoleSink() *WriterSink {
+	return g.consoleSink
+}
+
+// FileSink
+//
+//	@Description: 获取日志文件输出端，未设置LogDir、LogName时为nil
+//	@receiver g
+//	@return *FileSink
+func (g *GoLog) FileSink() *FileSink {
+	return g.fileSink
 }
 
 func (g *GoLog) SetLogFormatter(f func(entry *LogEntity) string) {
-	g.RLock()
-	defer g.RUnlock()
-	g.logFormatter = f
+	g.update(func(c *logConfig) {
+		c.logFormatter = f
+	})
 }
 
-func (g *GoLog) SetLogDir(logDir string) {
-	g.RLock()
-	defer g.RUnlock()
-	g.logDir = logDir
+// SetLogPattern
+//
+//	@Description: 设置转换模式，未设置自定义格式化器时生效，为空时恢复默认的列格式
+//	@receiver g
+//	@param pattern @See CompilePattern
+//	@return error
+func (g *GoLog) SetLogPattern(pattern string) error {
+	var layout *PatternLayout
+	if pattern != "" {
+		var err error
+		if layout, err = CompilePattern(pattern); err != nil {
+			return err
+		}
+	}
+	g.update(func(c *logConfig) {
+		c.pattern = layout
+	})
+	return nil
+}
+
+// SetVModule
+//
+//	@Description: 按调用者文件或包路径覆盖日志级别（类似glog的--vmodule），如"rotation*=DEBUG,net/http/*=TRACE"；
+//	不包含"/"的pattern匹配不带.go后缀的文件名，包含"/"的pattern匹配文件路径、所在目录或包导入路径的末尾若干段，
+//	级别可以是日志级别名称或严重程度数值，为空时取消覆盖
+//	@receiver g
+//	@param spec
+//	@return error
+func (g *GoLog) SetVModule(spec string) error {
+	vm, err := parseVModule(spec)
+	if err != nil {
+		return err
+	}
+	g.update(func(c *logConfig) {
+		c.vmodule = vm
+	})
+	return nil
 }
 
-func (g *GoLog) setLogName(logName string) {
-	g.RLock()
-	defer g.RUnlock()
-	g.logName = logName
+// SetLogDir
+//
+//	@Description: 修改日志存放目录，未设置日志文件时不做处理
+//	@receiver g
+//	@param logDir
+func (g *GoLog) SetLogDir(logDir string) {
+	if g.fileSink != nil {
+		g.fileSink.setLogDir(logDir)
+	}
 }
 
 func (g *GoLog) ShortLogEnable(shortLog bool) {
-	g.RLock()
-	defer g.RUnlock()
-	g.shortLogEnable = shortLog
+	g.update(func(c *logConfig) {
+		c.shortLogEnable = shortLog
+	})
 }
 
 func (g *GoLog) ConsoleEnable(console bool) {
-	g.RLock()
-	defer g.RUnlock()
-	g.consoleEnable = console
+	g.update(func(c *logConfig) {
+		c.consoleEnable = console
+	})
 }
 
 func (g *GoLog) ColorEnable(color bool) {
-	g.RLock()
-	defer g.RUnlock()
-	g.colorEnable = color
+	g.consoleSink.SetColorEnable(color)
 }
 
+// Destroy
+//
+//	@Description: 关闭日志并等待完成 @See GoLog.Close
+//	@receiver g
 func (g *GoLog) Destroy() {
-	if g.closeFlag == true {
+	if g.parent != nil {
 		return
 	}
-	g.closeFlag = true
-	close(g.msgChan)
-	if g.compressChan != nil {
-		close(g.compressChan)
-	}
-	g.waiter.Wait()
-
+	g.destroy()
 }
 
 // formatMsg
 //
 //	@Description: 格式化日志明细
-//	@receiver g
-//	@param level
-//	@param file
-//	@param line
-//	@param msg
+//	@param entry
+//	@param color 是否输出颜色
 //	@return string
-func (g *GoLog) formatMsg(entry *LogEntity) string {
-	var detail string
-	if g.colorEnable {
-		var color Color
-		switch entry.LogLevel {
-		case LoglevelTrace:
-			color = Blue
-		case LoglevelDebug:
-			color = Magenta
-		case LoglevelInfo:
-			color = Green
-		case LoglevelWarn:
-			color = Yellow
-		case LoglevelError:
-			color = Red
-		default:
-			color = White
-		}
-		detail = fmt.Sprint(
-			Cyan.WithColorEnd(entry.LogTime.Format(string(DefaultLayout))),
-			fmt.Sprintf("%18s", " ["+color.WithColorEnd(string(entry.LogLevel))+"] "),
-			fmt.Sprintf("%30s", entry.LogFile+":"+strconv.Itoa(entry.LineNum)+":\t"),
-			entry.Msg,
-		)
-	} else {
-		detail = fmt.Sprint(
-			entry.LogTime.Format(string(DefaultLayout)),
-			fmt.Sprintf("%18s", " ["+entry.LogLevel+"] "),
-			fmt.Sprintf("%30s", entry.LogFile+":"+strconv.Itoa(entry.LineNum)+":\t"),
-			entry.Msg,
-		)
+func formatMsg(entry *LogEntity, color bool) string {
+	bp := bufPool.Get().(*[]byte)
+	buf := (*bp)[:0]
+	if color {
+		buf = append(buf, Cyan...)
 	}
+	buf = entry.LogTime.AppendFormat(buf, string(DefaultLayout))
+	if color {
+		buf = append(buf, Reset...)
+	}
+	// 级别右对齐到18个字符，彩色输出时颜色码也计入宽度
+	start := len(buf)
+	buf = append(buf, " ["...)
+	if color {
+		buf = append(buf, entry.LogLevel.Color()...)
+	}
+	buf = append(buf, entry.LogLevel...)
+	if color {
+		buf = append(buf, Reset...)
+	}
+	buf = padLeft(append(buf, "] "...), start, 18)
+	start = len(buf)
+	buf = append(buf, entry.LogFile...)
+	buf = append(buf, ':')
+	buf = strconv.AppendInt(buf, int64(entry.LineNum), 10)
+	buf = padLeft(append(buf, ":\t"...), start, 30)
+	buf = append(buf, entry.Msg...)
+	if len(entry.Fields) > 0 {
+		buf = append(buf, ' ')
+		buf = append(buf, entry.Fields.String()...)
+	}
+	buf = append(buf, '\n')
+	s := string(buf)
+	putBuf(bp, buf)
+	return s
+}
 
-	return detail + "\n"
+// padLeft
+//
+//	@Description: 将buf[start:]左侧补空格到width个字符，与fmt的%*s一致
+//	@param buf
+//	@param start
+//	@param width
+//	@return []byte
+func padLeft(buf []byte, start, width int) []byte {
+	n := width - utf8.RuneCount(buf[start:])
+	if n <= 0 {
+		return buf
+	}
+	buf = append(buf, make([]byte, n)...)
+	copy(buf[start+n:], buf[start:len(buf)-n])
+	for i := start; i < start+n; i++ {
+		buf[i] = ' '
+	}
+	return buf
 }
 
 // fileIdx
@@ -336,7 +885,7 @@ func (g *GoLog) formatMsg(entry *LogEntity) string {
 //	@param file 文件绝对地址
 //	@return string 文件地址
 func (g *GoLog) fileIdx(file string) string {
-	if !g.shortLogEnable {
+	if !g.config.Load().shortLogEnable {
 		return file
 	}
 	short := file
@@ -355,195 +904,188 @@ func (g *GoLog) fileIdx(file string) string {
 //	@Description: 消费消息管道的消息
 //	@receiver g
 func (g *GoLog) consumeMsgChan() {
-	g.waiter.Add(1)
-	//  目录、文件名不为空 切没有结尾斜杠
-	if g.logDir != "" && g.logName != "" && !(strings.HasSuffix(g.logDir, "/") || strings.HasSuffix(g.logDir, "\\")) {
-		g.SetLogDir(g.logDir + "/")
-		g.setLogName(g.logDir + g.logName)
-	}
-
-	if g.rollLogByTime != 0 || g.rollLogBySize != 0 {
-		go g.compressLogFile()
-		if g.compressChan == nil {
-			g.compressChan = make(chan string, 2)
-		}
-	}
+	ticker := time.NewTicker(dropReportInterval)
+	defer ticker.Stop()
 	for {
 		select {
-		case msg, ok := <-g.msgChan:
+		case entry, ok := <-g.msgChan:
 			if !ok { //此时说明管道已经关闭
+				g.lastDropReport = time.Time{}
+				g.reportDropped()
+				g.closeSinks()
 				g.waiter.Done()
 				return
 			}
-			if g.consoleEnable {
-				_, _ = os.Stdout.WriteString(msg)
-			}
-			if g.writer != nil {
-				_, _ = g.writer.Write([]byte(msg))
-			}
-			if g.logDir == "" || g.logName == "" {
-				continue
+			g.writeEntry(entry)
+			releaseEntity(entry)
+			g.reportDropped()
+		case req := <-g.flushChan:
+			g.drainMsgChan()
+			g.reportDropped()
+			var err error
+			if req.sync {
+				err = g.syncSinks()
 			}
-			file := g.getLogFile()
-			if file == nil {
-				continue
+			if req.fn != nil {
+				g.writeLock.Lock()
+				req.fn()
+				g.writeLock.Unlock()
 			}
-			n, err := file.WriteString(msg)
-			if err != nil {
-				_, _ = os.Stderr.WriteString("write log to " + g.logName + " failed,err:" + err.Error() + "\tdata:" + msg)
-			}
-			g.logFileSize += int64(n)
+			req.done <- err
+		case <-ticker.C:
+			g.reportDropped()
 		}
 	}
 }
 
-// compressLogFile
+// writeEntry
 //
-//	@Description: 异步压缩文件
+//	@Description: 将一条日志分发给控制台及各输出端
 //	@receiver g
-//	@Author yuhao
-//	@Data 2023-02-28 11:30:47
-func (g *GoLog) compressLogFile() {
-	g.waiter.Add(1)
+//	@param entry
+func (g *GoLog) writeEntry(entry *LogEntity) {
+	g.writeLock.Lock()
+	defer g.writeLock.Unlock()
+	g.resolve(entry)
+	if g.config.Load().consoleEnable {
+		g.writeSink(g.consoleSink, entry)
+	}
+	for _, sink := range g.sinks {
+		g.writeSink(sink, entry)
+	}
+}
+
+// writeSink
+//
+//	@Description: 输出端启用了该级别时写出日志，失败时输出到标准错误
+//	@receiver g
+//	@param sink
+//	@param entry
+func (g *GoLog) writeSink(sink Sink, entry *LogEntity) {
+	if !sink.Enabled(entry.LogLevel) {
+		return
+	}
+	if err := sink.Write(entry); err != nil {
+		_, _ = os.Stderr.WriteString("write log failed,err:" + err.Error() + "\tdata:" + entry.Msg + "\n")
+	}
+}
+
+// drainMsgChan
+//
+//	@Description: 写出管道中已有的全部日志，不等待新的日志
+//	@receiver g
+func (g *GoLog) drainMsgChan() {
 	for {
 		select {
-		case s, ok := <-g.compressChan:
+		case entry, ok := <-g.msgChan:
 			if !ok {
-				g.waiter.Done()
 				return
 			}
-			file, err := os.Open(s)
-			if err != nil {
-				_, _ = os.Stderr.WriteString("open file " + s + " failed,err:" + err.Error())
-				continue
-			}
-			err = Compress([]*os.File{file}, s+".zip")
-			if err != nil {
-				_, _ = os.Stderr.WriteString("Compress file " + s + ".zip" + " failed,err:" + err.Error())
-			}
-			_ = os.Remove(s)
+			g.writeEntry(entry)
+			releaseEntity(entry)
+		default:
+			return
 		}
 	}
-
 }
 
-// getLogFile
+// syncSinks
 //
-//	@Description: 获取文件句柄
+//	@Description: 将各输出端刷入磁盘，某个输出端失败时继续处理其余的输出端
 //	@receiver g
-//	@return *os.File
-func (g *GoLog) getLogFile() *os.File {
-	fileInfo, err := os.Stat(g.logName)
-	if os.IsNotExist(err) { //文件不存在
-		file, err := os.Create(g.logName)
-		if err != nil {
-			_, _ = os.Stderr.WriteString("create logfile " + g.logName + " failed,err:" + err.Error())
-			return nil
-		}
-		g.logFile = file
-		return file
-	}
-	//  在同一个时间块但是还没打开
-	if g.logFile == nil {
-		file, err := os.OpenFile(g.logName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
-		if err != nil {
-			_, _ = os.Stderr.WriteString("open logfile " + g.logName + " failed,err:" + err.Error())
-			return nil
+//	@return error 第一个失败的错误
+func (g *GoLog) syncSinks() error {
+	g.writeLock.Lock()
+	defer g.writeLock.Unlock()
+	var first error
+	for _, sink := range g.sinks {
+		if err := sink.Sync(); err != nil && first == nil {
+			first = err
 		}
-		g.logFile = file
-	}
-	//  文件存在 根据时间滚动文件
-	if g.rollLogByTime != 0 {
-		return g.getFileByTime(fileInfo)
-	}
-	//  文件存在 根据文件的大小滚动文件
-	if g.rollLogBySize != 0 {
-		return g.getFileBySize(fileInfo)
 	}
-
-	return g.logFile
+	return first
 }
 
-// getFileByTime
+// closeSinks
 //
-//	@Description: 根据时间滚动文件
+//	@Description: 关闭各输出端，日志文件会刷盘并等待已提交的压缩任务完成
 //	@receiver g
-//	@param fileInfo
-//	@return *os.File
-func (g *GoLog) getFileByTime(fileInfo os.FileInfo) *os.File {
-	now := time.Now().Unix()
-	duration := int64(g.rollLogByTime.Seconds())
-	format := time.Unix(now/duration*duration, 0).Format(string(DateTimeLayout4))
-	if g.lastTimeBlock == "" {
-		g.lastTimeBlock = fileInfo.ModTime().Format(string(DateTimeLayout4))
-	}
-	//  不在同一时间块
-	if g.lastTimeBlock != format {
-		// 如果文件被打开需要关闭
-		if g.logFile != nil {
-			_ = g.logFile.Close()
-			g.logFile = nil
-		}
-		err := os.Rename(g.logName, g.logName+"-"+g.lastTimeBlock)
-		if err != nil {
-			_, _ = os.Stderr.WriteString("Rename " + g.logName + " failed,err:" + err.Error())
-			return nil
-		}
-		g.compressChan <- g.logName + "-" + g.lastTimeBlock
-		file, err := os.Create(g.logName)
-		if err != nil {
-			_, _ = os.Stderr.WriteString("create logfile " + g.logName + " failed,err:" + err.Error())
-			return nil
+func (g *GoLog) closeSinks() {
+	g.writeLock.Lock()
+	defer g.writeLock.Unlock()
+	for _, sink := range g.sinks {
+		if err := sink.Close(); err != nil {
+			_, _ = os.Stderr.WriteString("close log failed,err:" + err.Error() + "\n")
+			if g.closeErr == nil {
+				g.closeErr = err
+			}
 		}
-		g.lastTimeBlock = format
-		g.logFile = file
-		return file
 	}
+}
 
-	return g.logFile
+// exec
+//
+//	@Description: 写出管道中已有的日志后在消费协程中执行fn，并等待执行完成，日志关闭后不执行
+//	@receiver g
+//	@param fn
+func (g *GoLog) exec(fn func()) {
+	if !g.acquire() {
+		return
+	}
+	defer g.release()
+	req := flushRequest{done: make(chan error, 1), fn: fn}
+	g.flushChan <- req
+	<-req.done
 }
 
-// getFileBySize
+// Reopen
 //
-//	@Description: 根据文件大小滚动文件
+//	@Description: 在调用前写入管道的日志写出后，重新打开支持Reopener的输出端，如日志文件；日志销毁后调用不做处理
 //	@receiver g
-//	@param fileInfo
-//	@return *os.File
-func (g *GoLog) getFileBySize(fileInfo os.FileInfo) *os.File {
-	sizeKB := fileInfo.Size() / 1024
-	// 文件大小超过滚动的大小了需要重命名滚动
-	if g.rollLogBySize < sizeKB {
-		// 如果文件被打开需要关闭
-		if g.logFile != nil {
-			_ = g.logFile.Close()
-			g.logFile = nil
-		}
-		fileInfos, err := ioutil.ReadDir(g.logDir)
-		if err != nil {
-			_, _ = os.Stderr.WriteString("ReadDir " + g.logDir + " failed,err:" + err.Error())
-			return nil
-		}
-		cnt := 1 //获取文件夹中已经存在多少logName文件了
-		for _, info := range fileInfos {
-			if strings.HasPrefix(info.Name(), g.logName) && strings.HasSuffix(info.Name(), ".zip") {
-				cnt++
+//	@return error 第一个重新打开失败的错误
+func (g *GoLog) Reopen() error {
+	var first error
+	g.exec(func() {
+		for _, sink := range g.sinks {
+			if r, ok := sink.(Reopener); ok {
+				if err := r.Reopen(); err != nil && first == nil {
+					first = err
+				}
 			}
 		}
+	})
+	return first
+}
 
-		err = os.Rename(g.logName, g.logName+"-"+strconv.Itoa(cnt))
-		if err != nil {
-			_, _ = os.Stderr.WriteString("Rename " + g.logName + " failed,err:" + err.Error())
-			return nil
-		}
+// Flush
+//
+//	@Description: 阻塞直到调用前写入管道的日志全部由各输出端写出，日志销毁后调用不做处理
+//	@receiver g
+func (g *GoLog) Flush() {
+	_ = g.flush(false)
+}
 
-		g.compressChan <- g.logName + "-" + strconv.Itoa(cnt)
-		file, err := os.Create(g.logName)
-		if err != nil {
-			_, _ = os.Stderr.WriteString("create logfile " + g.logName + " failed,err:" + err.Error())
-			return nil
-		}
-		g.logFile = file
-		return file
+// Sync
+//
+//	@Description: 在Flush的基础上将各输出端刷入磁盘，如对日志文件执行fsync
+//	@receiver g
+//	@return error 第一个刷盘失败的错误
+func (g *GoLog) Sync() error {
+	return g.flush(true)
+}
+
+// flush
+//
+//	@Description: 阻塞直到调用前写入管道的日志全部输出
+//	@receiver g
+//	@param sync 是否同时将日志文件刷入磁盘
+//	@return error 刷盘失败的错误
+func (g *GoLog) flush(sync bool) error {
+	if !g.acquire() {
+		return nil
 	}
-	return g.logFile
+	defer g.release()
+	req := flushRequest{done: make(chan error, 1), sync: sync}
+	g.flushChan <- req
+	return <-req.done
 }
@@ -1,7 +1,10 @@
 package go_log
 
 import (
+	"context"
 	"io"
+	"runtime"
+	"strings"
 	"time"
 )
 
@@ -11,12 +14,22 @@ type TimeLayOut string //时间格式
 
 type Color string //颜色
 
+type LogFormat string //日志输出格式
+
 const (
 	LoglevelTrace LogLevel = "TRACE"
 	LoglevelDebug LogLevel = "DEBUG"
 	LoglevelInfo  LogLevel = "INFO"
 	LoglevelWarn  LogLevel = "WARN"
 	LoglevelError LogLevel = "ERROR"
+	LoglevelPanic LogLevel = "PANIC" //输出日志后panic
+	LoglevelFatal LogLevel = "FATAL" //输出日志后退出进程
+)
+
+const (
+	LogFormatText   LogFormat = "text"   //默认的文本格式
+	LogFormatJson   LogFormat = "json"   //json格式 @See NewJsonFormatter
+	LogFormatLogfmt LogFormat = "logfmt" //logfmt格式 @See NewLogfmtFormatter
 )
 
 const (
@@ -49,9 +62,9 @@ const (
 
 // LevelNum
 //
-//	@Description: 获取日志级别字符串
+//	@Description: 获取日志级别的严重程度，数值越大越严重，未注册的级别返回-1 @See RegisterLevel
 //	@receiver l
-//	@return string
+//	@return int8
 func (l LogLevel) LevelNum() int8 {
 	switch l {
 	case LoglevelTrace:
@@ -64,11 +77,47 @@ func (l LogLevel) LevelNum() int8 {
 		return 4
 	case LoglevelError:
 		return 5
+	case LoglevelPanic:
+		return 6
+	case LoglevelFatal:
+		return 7
 	default:
+		if info, ok := customLevel(l); ok {
+			return info.severity
+		}
 		return -1
 	}
 }
 
+// Color
+//
+//	@Description: 获取日志级别对应的颜色
+//	@receiver l
+//	@return Color
+func (l LogLevel) Color() Color {
+	switch l {
+	case LoglevelTrace:
+		return Blue
+	case LoglevelDebug:
+		return Magenta
+	case LoglevelInfo:
+		return Green
+	case LoglevelWarn:
+		return Yellow
+	case LoglevelError:
+		return Red
+	case LoglevelPanic:
+		return MagentaBold
+	case LoglevelFatal:
+		return RedBold
+	default:
+		if info, ok := customLevel(l); ok && info.color != "" {
+			return info.color
+		}
+		return White
+	}
+}
+
 // String
 //
 //	@Description: 获取时间字符串
@@ -115,24 +164,71 @@ type ILogger interface {
 	Warn(format string, msg ...any)
 	// Error Error级别日志
 	Error(format string, msg ...any)
+	// Panic Panic级别日志，日志写出后以日志内容panic
+	Panic(format string, msg ...any)
+	// Fatal Fatal级别日志，写出管道中的全部日志、关闭日志文件、执行退出钩子后以状态码1退出进程
+	Fatal(format string, msg ...any)
+	// Tracew 带有key/value字段的Trace级别日志，keysAndValues为交替出现的key、value
+	Tracew(msg string, keysAndValues ...any)
+	// Debugw 带有key/value字段的Debug级别日志
+	Debugw(msg string, keysAndValues ...any)
+	// Infow 带有key/value字段的Info级别日志
+	Infow(msg string, keysAndValues ...any)
+	// Warnw 带有key/value字段的Warn级别日志
+	Warnw(msg string, keysAndValues ...any)
+	// Errorw 带有key/value字段的Error级别日志
+	Errorw(msg string, keysAndValues ...any)
+	// Log 指定级别的日志，级别可以是通过RegisterLevel注册的自定义级别，未注册的级别不会输出
+	Log(level LogLevel, format string, msg ...any)
+	// Panicw 带有key/value字段的Panic级别日志
+	Panicw(msg string, keysAndValues ...any)
+	// Fatalw 带有key/value字段的Fatal级别日志
+	Fatalw(msg string, keysAndValues ...any)
+	// TraceCtx Trace级别日志，附加通过RegisterContextExtractor注册的提取器从ctx中提取的字段
+	TraceCtx(ctx context.Context, format string, msg ...any)
+	// DebugCtx Debug级别日志，附加从ctx中提取的字段
+	DebugCtx(ctx context.Context, format string, msg ...any)
+	// InfoCtx Info级别日志，附加从ctx中提取的字段
+	InfoCtx(ctx context.Context, format string, msg ...any)
+	// WarnCtx Warn级别日志，附加从ctx中提取的字段
+	WarnCtx(ctx context.Context, format string, msg ...any)
+	// ErrorCtx Error级别日志，附加从ctx中提取的字段
+	ErrorCtx(ctx context.Context, format string, msg ...any)
+	// PanicCtx Panic级别日志，附加从ctx中提取的字段
+	PanicCtx(ctx context.Context, format string, msg ...any)
+	// FatalCtx Fatal级别日志，附加从ctx中提取的字段
+	FatalCtx(ctx context.Context, format string, msg ...any)
+	// With 派生一个绑定了字段的子日志，子日志与父日志共享管道、输出流、滚动和日志级别
+	With(keysAndValues ...any) ILogger
 	// SetLogLevel 设置日志级别
 	SetLogLevel(loglevel LogLevel)
-	// SetLohWriter 设置输出流
+	// SetLohWriter 设置输出流，输出流不输出颜色
 	SetLohWriter(writer io.Writer)
+	// AddSink 添加输出端，每个输出端有自己的最低日志级别、格式化器及颜色配置
+	AddSink(sink Sink)
 	// SetLogFormatter 日志格式化器
 	SetLogFormatter(func(entry *LogEntity) string)
 	// ShortLogEnable 是否使用短日志（true则只包含调用者的相对路径）
 	ShortLogEnable(shortLog bool)
 	// ConsoleEnable 是否允许控制台输出
 	ConsoleEnable(console bool)
-	// ColorEnable 是否需要彩色输出
+	// ColorEnable 控制台是否需要彩色输出
 	ColorEnable(color bool)
-	// Destroy 销毁
+	// Flush 阻塞直到调用前的日志全部写出，不会销毁日志
+	Flush()
+	// Sync 阻塞直到调用前的日志全部写出并刷入磁盘，返回第一个刷盘失败的错误
+	Sync() error
+	// Reopen 重新打开支持Reopener的输出端，如日志文件被logrotate等外部工具移动后重新打开，返回第一个失败的错误
+	Reopen() error
+	// Close 关闭日志，等待调用前的日志全部写出、输出端关闭，ctx超时返回ctx.Err()；可重复调用，关闭后的日志写到标准错误，
+	// 对With派生的子日志调用时不做任何处理
+	Close(ctx context.Context) error
+	// Destroy 销毁，等同于Close(context.Background())，对With派生的子日志调用时不做任何处理
 	Destroy()
 }
 
 // LogEntity
-// @Description: 日志消息体
+// @Description: 日志消息体，由对象池复用，输出端及格式化器不能在返回后继续持有
 // @Data 2023-02-27 10:07:03
 type LogEntity struct {
 	LogTime  time.Time //日志时间
@@ -140,4 +236,30 @@ type LogEntity struct {
 	LogFile  string    //产生日志的文件
 	LineNum  int       //行号
 	Msg      string    // 日志内容
+	Fields   Fields    //结构化字段
+	pc       uintptr   //调用者的程序计数器，在消费协程中解析出文件、行号及函数名
+	function string    //调用者函数全名
+	format   string    //延迟格式化的格式
+	args     []any     //延迟格式化的参数，只包含不可变的基础类型
+	lazy     bool      //Msg需要在消费协程中由format、args生成
+}
+
+// FuncName
+//
+//	@Description: 获取产生日志的函数名，如 go_log.TestDemo1，无法获取时返回空字符串
+//	@receiver e
+//	@return string
+func (e *LogEntity) FuncName() string {
+	name := e.function
+	if name == "" {
+		if e.pc == 0 {
+			return ""
+		}
+		fn := runtime.FuncForPC(e.pc)
+		if fn == nil {
+			return ""
+		}
+		name = fn.Name()
+	}
+	return name[strings.LastIndexByte(name, '/')+1:]
 }
@@ -0,0 +1,337 @@
+package go_log
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type TimeEncoding string //时间编码方式
+
+const (
+	TimeEncodingRFC3339     TimeEncoding = "rfc3339"      //RFC3339格式，精确到纳秒
+	TimeEncodingEpoch       TimeEncoding = "epoch"        //unix时间戳，单位秒，小数部分为秒以下的精度
+	TimeEncodingEpochMillis TimeEncoding = "epoch_millis" //unix时间戳，单位毫秒
+	TimeEncodingEpochNanos  TimeEncoding = "epoch_nanos"  //unix时间戳，单位纳秒
+	TimeEncodingLayout      TimeEncoding = "layout"       //使用自定义的TimeLayout格式化
+)
+
+// JsonFormatConfig
+// @Description: json格式化器配置，key为空时使用默认名称，为"-"时不输出该key
+type JsonFormatConfig struct {
+	TimeKey       string       `json:"time_key"`       //时间的key，默认ts
+	LevelKey      string       `json:"level_key"`      //日志级别的key，默认level
+	CallerKey     string       `json:"caller_key"`     //调用者的key，默认caller
+	MsgKey        string       `json:"msg_key"`        //日志内容的key，默认msg
+	FieldsKey     string       `json:"fields_key"`     //不平铺时结构化字段的key，默认fields
+	TimeEncoding  TimeEncoding `json:"time_encoding"`  //时间编码方式，默认rfc3339
+	TimeLayout    TimeLayOut   `json:"time_layout"`    //TimeEncoding为layout时使用的时间格式，默认DefaultLayout
+	FlattenFields bool         `json:"flatten_fields"` //是否将结构化字段平铺到顶层
+}
+
+// jsonFormatter
+// @Description: json格式化器，常见类型直接编码，不经过反射
+type jsonFormatter struct {
+	timeKey       string
+	levelKey      string
+	callerKey     string
+	msgKey        string
+	fieldsKey     string
+	timeEncoding  TimeEncoding
+	timeLayout    string
+	flattenFields bool
+}
+
+var bufPool = sync.Pool{New: func() any {
+	buf := make([]byte, 0, 256)
+	return &buf
+}}
+
+// putBuf
+//
+//	@Description: 归还缓冲区，过大的缓冲区直接丢弃避免长期占用内存
+//	@param bp
+//	@param buf
+func putBuf(bp *[]byte, buf []byte) {
+	if cap(buf) > 64*1024 {
+		return
+	}
+	*bp = buf
+	bufPool.Put(bp)
+}
+
+// NewJsonFormatter
+//
+//	@Description: 创建json格式化器，可通过SetLogFormatter或GoLogConfig.LogFormat使用
+//	@param config 为nil时使用默认配置
+//	@return func(entry *LogEntity) string
+func NewJsonFormatter(config *JsonFormatConfig) func(entry *LogEntity) string {
+	if config == nil {
+		config = &JsonFormatConfig{}
+	}
+	f := &jsonFormatter{
+		timeKey:       defaultKey(config.TimeKey, "ts"),
+		levelKey:      defaultKey(config.LevelKey, "level"),
+		callerKey:     defaultKey(config.CallerKey, "caller"),
+		msgKey:        defaultKey(config.MsgKey, "msg"),
+		fieldsKey:     defaultKey(config.FieldsKey, "fields"),
+		timeEncoding:  config.TimeEncoding,
+		timeLayout:    string(config.TimeLayout),
+		flattenFields: config.FlattenFields,
+	}
+	if f.timeEncoding == "" {
+		f.timeEncoding = TimeEncodingRFC3339
+		if f.timeLayout != "" {
+			f.timeEncoding = TimeEncodingLayout
+		}
+	}
+	if f.timeLayout == "" {
+		f.timeLayout = string(DefaultLayout)
+	}
+	return f.format
+}
+
+// defaultKey
+//
+//	@Description: key为空时返回默认值，为"-"时返回空表示不输出
+//	@param key
+//	@param def
+//	@return string
+func defaultKey(key, def string) string {
+	switch key {
+	case "":
+		return def
+	case "-":
+		return ""
+	default:
+		return key
+	}
+}
+
+// format
+//
+//	@Description: 格式化为一行json
+//	@receiver f
+//	@param entry
+//	@return string
+func (f *jsonFormatter) format(entry *LogEntity) string {
+	bp := bufPool.Get().(*[]byte)
+	buf := (*bp)[:0]
+	buf = append(buf, '{')
+	if f.timeKey != "" {
+		buf = appendJsonKey(buf, f.timeKey)
+		buf = appendTime(buf, entry.LogTime, f.timeEncoding, f.timeLayout, true)
+	}
+	if f.levelKey != "" {
+		buf = appendJsonKey(buf, f.levelKey)
+		buf = appendJsonString(buf, string(entry.LogLevel))
+	}
+	if f.callerKey != "" && entry.LogFile != "" {
+		buf = appendJsonKey(buf, f.callerKey)
+		buf = appendJsonString(buf, entry.LogFile+":"+strconv.Itoa(entry.LineNum))
+	}
+	if f.msgKey != "" {
+		buf = appendJsonKey(buf, f.msgKey)
+		buf = appendJsonString(buf, entry.Msg)
+	}
+	if len(entry.Fields) > 0 {
+		if !f.flattenFields && f.fieldsKey != "" {
+			buf = appendJsonKey(buf, f.fieldsKey)
+			buf = append(buf, '{')
+		}
+		for _, field := range entry.Fields {
+			buf = appendJsonKey(buf, field.Key)
+			buf = appendJsonValue(buf, field.Value)
+		}
+		if !f.flattenFields && f.fieldsKey != "" {
+			buf = append(buf, '}')
+		}
+	}
+	buf = append(buf, '}', '\n')
+	s := string(buf)
+	putBuf(bp, buf)
+	return s
+}
+
+// appendTime
+//
+//	@Description: 按照编码方式写入时间
+//	@param buf
+//	@param t
+//	@param encoding
+//	@param layout
+//	@param quote 字符串形式的时间是否需要加上json引号
+//	@return []byte
+func appendTime(buf []byte, t time.Time, encoding TimeEncoding, layout string, quote bool) []byte {
+	switch encoding {
+	case TimeEncodingEpoch:
+		return strconv.AppendFloat(buf, float64(t.UnixNano())/float64(time.Second), 'f', -1, 64)
+	case TimeEncodingEpochMillis:
+		return strconv.AppendInt(buf, t.UnixMilli(), 10)
+	case TimeEncodingEpochNanos:
+		return strconv.AppendInt(buf, t.UnixNano(), 10)
+	case TimeEncodingLayout:
+	default:
+		layout = time.RFC3339Nano
+	}
+	if !quote {
+		return t.AppendFormat(buf, layout)
+	}
+	buf = append(buf, '"')
+	buf = t.AppendFormat(buf, layout)
+	return append(buf, '"')
+}
+
+// appendJsonKey
+//
+//	@Description: 写入key及冒号，必要时在前面补上逗号
+//	@param buf
+//	@param key
+//	@return []byte
+func appendJsonKey(buf []byte, key string) []byte {
+	if last := buf[len(buf)-1]; last != '{' {
+		buf = append(buf, ',')
+	}
+	buf = appendJsonString(buf, key)
+	return append(buf, ':')
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJsonString
+//
+//	@Description: 写入json字符串，转义引号、反斜杠、控制字符以及U+2028、U+2029，非法的utf8字节替换为\ufffd
+//	@param buf
+//	@param s
+//	@return []byte
+func appendJsonString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				buf = append(buf, '\\', b)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			case '\b':
+				buf = append(buf, '\\', 'b')
+			case '\f':
+				buf = append(buf, '\\', 'f')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
+
+// appendJsonValue
+//
+//	@Description: 写入json值，常见类型直接编码，其余类型退化为json.Marshal
+//	@param buf
+//	@param value
+//	@return []byte
+func appendJsonValue(buf []byte, value any) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, "null"...)
+	case string:
+		return appendJsonString(buf, v)
+	case []byte:
+		return appendJsonString(buf, string(v))
+	case bool:
+		return strconv.AppendBool(buf, v)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int8:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int16:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int32:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case uint:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint8:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint16:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint32:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint64:
+		return strconv.AppendUint(buf, v, 10)
+	case float32:
+		return appendJsonFloat(buf, float64(v), 32)
+	case float64:
+		return appendJsonFloat(buf, v, 64)
+	case time.Time:
+		return appendTime(buf, v, TimeEncodingRFC3339, "", true)
+	case time.Duration:
+		return appendJsonString(buf, v.String())
+	case json.Marshaler:
+		bytes, err := v.MarshalJSON()
+		if err != nil {
+			return appendJsonString(buf, fmt.Sprint(v))
+		}
+		return append(buf, bytes...)
+	case error:
+		return appendJsonString(buf, v.Error())
+	case fmt.Stringer:
+		return appendJsonString(buf, v.String())
+	default:
+		bytes, err := json.Marshal(v)
+		if err != nil {
+			return appendJsonString(buf, fmt.Sprint(v))
+		}
+		return append(buf, bytes...)
+	}
+}
+
+// appendJsonFloat
+//
+//	@Description: 写入浮点数，NaN与Inf不是合法的json数字，以字符串形式输出
+//	@param buf
+//	@param f
+//	@param bitSize
+//	@return []byte
+func appendJsonFloat(buf []byte, f float64, bitSize int) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return appendJsonString(buf, strconv.FormatFloat(f, 'g', -1, bitSize))
+	}
+	return strconv.AppendFloat(buf, f, 'g', -1, bitSize)
+}
@@ -0,0 +1,123 @@
+package go_log
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// levelInfo
+// @Description: 自定义日志级别的信息
+type levelInfo struct {
+	severity int8  //严重程度
+	color    Color //颜色
+}
+
+// builtinLevels 内置的日志级别，按严重程度排序
+var builtinLevels = []LogLevel{
+	LoglevelTrace, LoglevelDebug, LoglevelInfo, LoglevelWarn, LoglevelError, LoglevelPanic, LoglevelFatal,
+}
+
+var levelLock = sync.Mutex{}
+var customLevels atomic.Value //map[LogLevel]levelInfo，写时复制，读取无需加锁
+
+// RegisterLevel
+//
+//	@Description: 注册自定义日志级别，如 RegisterLevel("AUDIT", 8, go_log.Cyan) 注册一个比Fatal更严重、不会被过滤的级别；
+//	名称统一转为大写，内置级别的严重程度为TRACE=1、DEBUG=2、INFO=3、WARN=4、ERROR=5、PANIC=6、FATAL=7，
+//	自定义级别可以与内置级别的严重程度相同，重复注册同名的自定义级别会覆盖之前的配置
+//	@param name 级别名称
+//	@param severity 严重程度，不能为负数
+//	@param color 颜色，为空时使用White
+//	@return LogLevel
+//	@return error
+func RegisterLevel(name string, severity int8, color Color) (LogLevel, error) {
+	level := LogLevel(strings.ToUpper(strings.TrimSpace(name)))
+	if level == "" || strings.ContainsAny(string(level), " \t\r\n") {
+		return "", errors.New("invalid level name " + name)
+	}
+	if severity < 0 {
+		return "", errors.New("invalid severity for level " + name)
+	}
+	for _, builtin := range builtinLevels {
+		if level == builtin {
+			return "", errors.New("level " + name + " is builtin")
+		}
+	}
+	levelLock.Lock()
+	defer levelLock.Unlock()
+	old, _ := customLevels.Load().(map[LogLevel]levelInfo)
+	levels := make(map[LogLevel]levelInfo, len(old)+1)
+	for k, v := range old {
+		levels[k] = v
+	}
+	levels[level] = levelInfo{severity: severity, color: color}
+	customLevels.Store(levels)
+	return level, nil
+}
+
+// customLevel
+//
+//	@Description: 查找自定义日志级别
+//	@param level
+//	@return levelInfo
+//	@return bool
+func customLevel(level LogLevel) (levelInfo, bool) {
+	levels, _ := customLevels.Load().(map[LogLevel]levelInfo)
+	info, ok := levels[level]
+	return info, ok
+}
+
+// ParseLevel
+//
+//	@Description: 解析日志级别，不区分大小写，支持内置级别与已注册的自定义级别
+//	@param s
+//	@return LogLevel
+//	@return error
+func ParseLevel(s string) (LogLevel, error) {
+	level := LogLevel(strings.ToUpper(strings.TrimSpace(s)))
+	if level.LevelNum() < 0 {
+		return "", errors.New("unknown log level " + s)
+	}
+	return level, nil
+}
+
+// Levels
+//
+//	@Description: 获取全部内置及自定义的日志级别，按严重程度排序
+//	@return []LogLevel
+func Levels() []LogLevel {
+	levels := append([]LogLevel{}, builtinLevels...)
+	custom, _ := customLevels.Load().(map[LogLevel]levelInfo)
+	for level := range custom {
+		levels = append(levels, level)
+	}
+	sort.SliceStable(levels, func(i, j int) bool {
+		if levels[i].LevelNum() != levels[j].LevelNum() {
+			return levels[i].LevelNum() < levels[j].LevelNum()
+		}
+		return levels[i] < levels[j]
+	})
+	return levels
+}
+
+// UnmarshalText
+//
+//	@Description: 从配置中解析日志级别，不区分大小写，为空时保持为空
+//	@receiver l
+//	@param text
+//	@return error
+func (l *LogLevel) UnmarshalText(text []byte) error {
+	if len(strings.TrimSpace(string(text))) == 0 {
+		*l = ""
+		return nil
+	}
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
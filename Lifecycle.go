@@ -0,0 +1,108 @@
+package go_log
+
+import (
+	"context"
+	"os"
+)
+
+// 日志的生命周期状态
+const (
+	stateRunning int32 = iota //运行中
+	stateClosing              //关闭中，不再接收新的日志，等待管道中的日志写出
+	stateClosed               //已关闭，输出端均已关闭
+)
+
+// Close
+//
+//	@Description: 关闭日志：不再接收新的日志，等待管道中的日志写出、输出端关闭；可重复调用，对With派生的子日志调用时不做任何处理；
+//	关闭开始后的日志会同步写到标准错误，不会panic
+//	@receiver g
+//	@param ctx 超时或取消时返回ctx.Err()，关闭会在后台继续完成
+//	@return error 超时返回ctx.Err()，否则返回第一个关闭失败的输出端的错误
+func (g *GoLog) Close(ctx context.Context) error {
+	if g.parent != nil {
+		return nil
+	}
+	if g.state.CompareAndSwap(stateRunning, stateClosing) {
+		go g.shutdown()
+	}
+	select {
+	case <-g.closed:
+		return g.closeErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shutdown
+//
+//	@Description: 等待正在写入管道的调用方退出后关闭管道，等待消费协程写出全部日志并关闭输出端
+//	@receiver g
+func (g *GoLog) shutdown() {
+	//  关闭开始后最后一个release会发出信号，可能收到之前残留的信号，因此需要重新检查
+	for g.inflight.Load() != 0 {
+		<-g.idle
+	}
+	close(g.msgChan)
+	g.waiter.Wait()
+	g.state.Store(stateClosed)
+	close(g.closed)
+}
+
+// destroy
+//
+//	@Description: 关闭并等待完成，子日志同样生效
+//	@receiver g
+func (g *GoLog) destroy() {
+	if g.state.CompareAndSwap(stateRunning, stateClosing) {
+		go g.shutdown()
+	}
+	<-g.closed
+}
+
+// running
+//
+//	@Description: 是否运行中
+//	@receiver g
+//	@return bool
+func (g *GoLog) running() bool {
+	return g.state.Load() == stateRunning
+}
+
+// acquire
+//
+//	@Description: 在写入管道或向消费协程发送请求前调用，运行中时保证管道在release前不会被关闭
+//	@receiver g
+//	@return bool 未运行时返回false，此时不能写入管道，也不需要调用release
+func (g *GoLog) acquire() bool {
+	g.inflight.Add(1)
+	if g.state.Load() != stateRunning {
+		g.release()
+		return false
+	}
+	return true
+}
+
+// release
+//
+//	@Description: 与acquire成对调用，关闭开始后最后一个退出的调用方通知shutdown
+//	@receiver g
+func (g *GoLog) release() {
+	if g.inflight.Add(-1) == 0 && g.state.Load() != stateRunning {
+		select {
+		case g.idle <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// writeLate
+//
+//	@Description: 关闭开始后的日志同步写到标准错误
+//	@receiver g
+//	@param entity
+func (g *GoLog) writeLate(entity *LogEntity) {
+	g.resolve(entity)
+	_, _ = os.Stderr.WriteString(formatMsg(entity, false))
+	releaseEntity(entity)
+}
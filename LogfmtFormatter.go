@@ -0,0 +1,132 @@
+package go_log
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// LogfmtFormatConfig
+// @Description: logfmt格式化器配置，key为空时使用默认名称，为"-"时不输出该key
+type LogfmtFormatConfig struct {
+	TimeKey      string       `json:"time_key"`      //时间的key，默认time
+	LevelKey     string       `json:"level_key"`     //日志级别的key，默认level
+	CallerKey    string       `json:"caller_key"`    //调用者的key，默认caller
+	MsgKey       string       `json:"msg_key"`       //日志内容的key，默认msg
+	TimeEncoding TimeEncoding `json:"time_encoding"` //时间编码方式，默认rfc3339
+	TimeLayout   TimeLayOut   `json:"time_layout"`   //TimeEncoding为layout时使用的时间格式，默认DefaultLayout
+}
+
+// logfmtFormatter
+// @Description: logfmt格式化器，输出形如 time=... level=INFO caller=demo_test.go:30 msg="..." key=value
+type logfmtFormatter struct {
+	timeKey      string
+	levelKey     string
+	callerKey    string
+	msgKey       string
+	timeEncoding TimeEncoding
+	timeLayout   string
+}
+
+// NewLogfmtFormatter
+//
+//	@Description: 创建logfmt格式化器，可通过SetLogFormatter或GoLogConfig.LogFormat使用
+//	@param config 为nil时使用默认配置
+//	@return func(entry *LogEntity) string
+func NewLogfmtFormatter(config *LogfmtFormatConfig) func(entry *LogEntity) string {
+	if config == nil {
+		config = &LogfmtFormatConfig{}
+	}
+	f := &logfmtFormatter{
+		timeKey:      defaultKey(config.TimeKey, "time"),
+		levelKey:     defaultKey(config.LevelKey, "level"),
+		callerKey:    defaultKey(config.CallerKey, "caller"),
+		msgKey:       defaultKey(config.MsgKey, "msg"),
+		timeEncoding: config.TimeEncoding,
+		timeLayout:   string(config.TimeLayout),
+	}
+	if f.timeEncoding == "" {
+		f.timeEncoding = TimeEncodingRFC3339
+		if f.timeLayout != "" {
+			f.timeEncoding = TimeEncodingLayout
+		}
+	}
+	if f.timeLayout == "" {
+		f.timeLayout = string(DefaultLayout)
+	}
+	return f.format
+}
+
+// format
+//
+//	@Description: 格式化为一行logfmt
+//	@receiver f
+//	@param entry
+//	@return string
+func (f *logfmtFormatter) format(entry *LogEntity) string {
+	bp := bufPool.Get().(*[]byte)
+	buf := (*bp)[:0]
+	if f.timeKey != "" {
+		buf = appendLogfmtKey(buf, f.timeKey)
+		if f.timeEncoding == TimeEncodingLayout {
+			buf = appendLogfmtValue(buf, entry.LogTime.Format(f.timeLayout))
+		} else {
+			buf = appendTime(buf, entry.LogTime, f.timeEncoding, f.timeLayout, false)
+		}
+	}
+	if f.levelKey != "" {
+		buf = appendLogfmtKey(buf, f.levelKey)
+		buf = appendLogfmtValue(buf, string(entry.LogLevel))
+	}
+	if f.callerKey != "" && entry.LogFile != "" {
+		buf = appendLogfmtKey(buf, f.callerKey)
+		buf = appendLogfmtValue(buf, entry.LogFile+":"+strconv.Itoa(entry.LineNum))
+	}
+	if f.msgKey != "" {
+		buf = appendLogfmtKey(buf, f.msgKey)
+		buf = appendLogfmtValue(buf, entry.Msg)
+	}
+	for _, field := range entry.Fields {
+		buf = appendLogfmtKey(buf, field.Key)
+		buf = appendLogfmtValue(buf, fieldValueString(field.Value))
+	}
+	buf = append(buf, '\n')
+	s := string(buf)
+	putBuf(bp, buf)
+	return s
+}
+
+// appendLogfmtKey
+//
+//	@Description: 写入key及等号，key中的空白、等号、引号替换为下划线
+//	@param buf
+//	@param key
+//	@return []byte
+func appendLogfmtKey(buf []byte, key string) []byte {
+	if len(buf) > 0 {
+		buf = append(buf, ' ')
+	}
+	if key == "" {
+		key = "_"
+	}
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f || r == utf8.RuneError {
+			buf = append(buf, '_')
+			continue
+		}
+		buf = utf8.AppendRune(buf, r)
+	}
+	return append(buf, '=')
+}
+
+// appendLogfmtValue
+//
+//	@Description: 写入值，为空或者包含空格、等号、引号、控制字符时加上引号并转义
+//	@param buf
+//	@param value
+//	@return []byte
+func appendLogfmtValue(buf []byte, value string) []byte {
+	if needsQuote(value) {
+		return strconv.AppendQuote(buf, value)
+	}
+	return append(buf, value...)
+}
@@ -0,0 +1,294 @@
+package go_log
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// NetConfig
+// @Description: 网络输出端配置
+type NetConfig struct {
+	SinkConfig                    //Formatter为空时使用所属日志的格式
+	Network         string        //网络类型：tcp、udp、unix、unixgram
+	Address         string        //地址，如127.0.0.1:5170、/var/run/app.sock
+	TLSConfig       *tls.Config   //不为空时使用TLS连接，只对tcp生效
+	DialTimeout     time.Duration //连接超时，为0时为5秒
+	WriteTimeout    time.Duration //写超时，为0时为5秒
+	MinBackoff      time.Duration //重连的初始间隔，每次失败翻倍，为0时为100毫秒
+	MaxBackoff      time.Duration //重连的最大间隔，为0时为30秒
+	RetryBufferSize int           //断开期间缓存的日志条数，写满时丢弃最早的未发送的日志，为0时为1024
+}
+
+// NetHealth
+// @Description: 网络输出端的健康状态
+type NetHealth struct {
+	Connected     bool      //是否已连接
+	Buffered      int       //等待重发的日志条数
+	Dropped       uint64    //因重发缓冲区已满而丢弃的日志条数
+	Reconnects    uint64    //断开后重新连接成功的次数
+	LastError     error     //最近一次连接或写入的错误
+	LastErrorTime time.Time //最近一次错误的时间
+}
+
+// NetSink
+// @Description: 输出到网络连接的输出端，断开后在后台按指数退避重连，期间的日志缓存在有界的重发缓冲区中，重连后按顺序补发
+type NetSink struct {
+	sinkBase
+	config  NetConfig
+	conn    net.Conn      //连接，断开时为nil
+	pending [][]byte      //等待发送的日志，按写入顺序排列
+	written int           //pending[0]在当前连接上已发送的字节数
+	backoff time.Duration //当前的重连间隔
+	retry   *time.Timer   //断开期间的重连定时器
+	dialing bool          //是否正在后台连接
+	health  NetHealth     //健康状态
+	closed  bool
+}
+
+// NewNetSink
+//
+//	@Description: 创建网络输出端并尝试建立连接，连接失败时不返回错误而是在后台重连
+//	@param config
+//	@return *NetSink
+//	@return error 配置不合法
+func NewNetSink(config NetConfig) (*NetSink, error) {
+	if config.Network == "" || config.Address == "" {
+		return nil, errors.New("network and address are required")
+	}
+	if config.TLSConfig != nil && config.Network != "tcp" && config.Network != "tcp4" && config.Network != "tcp6" {
+		return nil, errors.New("tls is not supported over " + config.Network)
+	}
+	if config.RetryBufferSize < 0 {
+		return nil, errors.New("invalid retry buffer size " + strconv.Itoa(config.RetryBufferSize))
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 5 * time.Second
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = 5 * time.Second
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = 100 * time.Millisecond
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 30 * time.Second
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = config.MinBackoff
+	}
+	if config.RetryBufferSize == 0 {
+		config.RetryBufferSize = 1024
+	}
+	s := &NetSink{sinkBase: newSinkBase(config.SinkConfig), config: config}
+	conn, err := s.dial()
+	s.Lock()
+	defer s.Unlock()
+	if err != nil {
+		s.fail(err)
+	} else {
+		s.conn = conn
+	}
+	return s, nil
+}
+
+// Health
+//
+//	@Description: 获取健康状态
+//	@receiver s
+//	@return NetHealth
+func (s *NetSink) Health() NetHealth {
+	s.Lock()
+	defer s.Unlock()
+	health := s.health
+	health.Connected = s.conn != nil
+	health.Buffered = len(s.pending)
+	return health
+}
+
+// Write
+//
+//	@Description: 格式化日志放入发送队列，已连接时发送，断开时只缓存，由后台重连后补发
+//	@receiver s
+//	@param entry
+//	@return error 连接断开时返回一次，断开期间不重复返回
+func (s *NetSink) Write(entry *LogEntity) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return errors.New("net sink " + s.config.Address + " is closed")
+	}
+	if n := len(s.pending); n >= s.config.RetryBufferSize {
+		s.health.Dropped++
+		switch {
+		case s.written == 0:
+			s.pending[0] = nil
+			s.pending = s.pending[1:]
+		case n > 1:
+			//  第一条日志已在当前连接上发送了一部分，需要发完，丢弃其后最早的一条
+			copy(s.pending[1:], s.pending[2:])
+			s.pending[n-1] = nil
+			s.pending = s.pending[:n-1]
+		default:
+			//  缓冲区只能容纳已部分发送的日志，丢弃新日志
+			return s.send()
+		}
+	}
+	s.pending = append(s.pending, []byte(s.format(entry)))
+	return s.send()
+}
+
+// Sync
+//
+//	@Description: 已连接时发送缓存的日志
+//	@receiver s
+//	@return error 仍有未发送的日志时返回最近一次的错误
+func (s *NetSink) Sync() error {
+	s.Lock()
+	defer s.Unlock()
+	_ = s.send()
+	if len(s.pending) > 0 {
+		if s.health.LastError != nil {
+			return s.health.LastError
+		}
+		return errors.New(strconv.Itoa(len(s.pending)) + " logs pending for " + s.config.Address)
+	}
+	return nil
+}
+
+// Close
+//
+//	@Description: 尝试发送缓存的日志后关闭连接，停止重连
+//	@receiver s
+//	@return error 有未发送的日志时返回错误
+func (s *NetSink) Close() error {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return nil
+	}
+	if s.conn != nil {
+		_ = s.send()
+	}
+	s.closed = true
+	if s.retry != nil {
+		s.retry.Stop()
+	}
+	// 正在进行的后台连接在完成后发现已关闭会自行关闭连接
+	if s.conn != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+	if n := len(s.pending); n > 0 {
+		s.pending = nil
+		return errors.New(strconv.Itoa(n) + " logs lost for " + s.config.Address)
+	}
+	return nil
+}
+
+// send
+//
+//	@Description: 在已建立的连接上按顺序发送等待中的日志，记录部分写入的字节数，调用方需持有锁
+//	@receiver s
+//	@return error 本次由连接状态变为断开时返回
+func (s *NetSink) send() error {
+	if s.conn == nil {
+		return nil
+	}
+	for len(s.pending) > 0 {
+		_ = s.conn.SetWriteDeadline(time.Now().Add(s.config.WriteTimeout))
+		n, err := s.conn.Write(s.pending[0][s.written:])
+		s.written += n
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() && s.config.TLSConfig == nil {
+				// 写超时后TCP连接仍可用，下次从未发送的部分继续；TLS连接写超时后不可再用，需要重连
+				s.health.LastError, s.health.LastErrorTime = err, time.Now()
+				return nil
+			}
+			_ = s.conn.Close()
+			s.conn = nil
+			// 新连接上需要重新发送完整的日志
+			s.written = 0
+			s.fail(err)
+			return errors.New("net sink " + s.config.Address + " disconnected,err:" + err.Error())
+		}
+		s.pending[0] = nil
+		s.pending = s.pending[1:]
+		s.written = 0
+	}
+	return nil
+}
+
+// dial
+//
+//	@Description: 建立连接，不需要持有锁
+//	@receiver s
+//	@return net.Conn
+//	@return error
+func (s *NetSink) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: s.config.DialTimeout}
+	if s.config.TLSConfig != nil {
+		return tls.DialWithDialer(dialer, s.config.Network, s.config.Address, s.config.TLSConfig)
+	}
+	return dialer.Dial(s.config.Network, s.config.Address)
+}
+
+// fail
+//
+//	@Description: 记录错误，翻倍重连间隔并启动重连定时器，调用方需持有锁
+//	@receiver s
+//	@param err
+func (s *NetSink) fail(err error) {
+	s.health.LastError = err
+	s.health.LastErrorTime = time.Now()
+	if s.backoff == 0 {
+		s.backoff = s.config.MinBackoff
+	} else if s.backoff *= 2; s.backoff > s.config.MaxBackoff {
+		s.backoff = s.config.MaxBackoff
+	}
+	if s.retry == nil {
+		s.retry = time.AfterFunc(s.backoff, s.reconnect)
+	} else {
+		s.retry.Reset(s.backoff)
+	}
+}
+
+// reconnect
+//
+//	@Description: 重连定时器到期时在后台连接，连接期间不持有锁，成功后补发缓存的日志
+//	@receiver s
+func (s *NetSink) reconnect() {
+	s.Lock()
+	if s.closed || s.conn != nil || s.dialing {
+		s.Unlock()
+		return
+	}
+	s.dialing = true
+	s.Unlock()
+
+	conn, err := s.dial()
+
+	s.Lock()
+	defer s.Unlock()
+	s.dialing = false
+	if s.closed {
+		if conn != nil {
+			_ = conn.Close()
+		}
+		return
+	}
+	if err != nil {
+		s.fail(err)
+		return
+	}
+	s.conn = conn
+	s.backoff = 0
+	s.health.Reconnects++
+	if err := s.send(); err != nil {
+		_, _ = os.Stderr.WriteString(err.Error() + "\n")
+	}
+}
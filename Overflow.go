@@ -0,0 +1,149 @@
+package go_log
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type OverflowPolicy string //消息管道写满时的处理策略
+
+const (
+	OverflowBlock        OverflowPolicy = "block"         //阻塞直到管道有空位，默认策略
+	OverflowBlockTimeout OverflowPolicy = "block_timeout" //阻塞至多BlockTimeout，超时后丢弃该日志
+	OverflowDropNewest   OverflowPolicy = "drop_newest"   //丢弃当前日志
+	OverflowDropOldest   OverflowPolicy = "drop_oldest"   //丢弃管道中最早的日志
+	OverflowSyncWrite    OverflowPolicy = "sync_write"    //在调用方协程中直接写出，可能先于管道中更早的日志输出
+)
+
+// DefaultBufferSize 未设置MsgChan、BufferSize时的缓冲区长度
+const DefaultBufferSize = 256
+
+// DefaultBlockTimeout block_timeout策略未设置BlockTimeout时的超时时间
+const DefaultBlockTimeout = 100 * time.Millisecond
+
+// dropReportInterval 两次输出丢弃日志数量之间的最小间隔
+const dropReportInterval = time.Second
+
+// parseOverflowPolicy
+//
+//	@Description: 解析管道写满时的处理策略，不区分大小写，"-"与"_"等价
+//	@param s 为空时返回OverflowBlock
+//	@return OverflowPolicy
+//	@return error
+func parseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch policy := OverflowPolicy(strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "-", "_")); policy {
+	case "":
+		return OverflowBlock, nil
+	case OverflowBlock, OverflowBlockTimeout, OverflowDropNewest, OverflowDropOldest, OverflowSyncWrite:
+		return policy, nil
+	default:
+		return "", errors.New("unknown overflow policy " + strconv.Quote(s))
+	}
+}
+
+// parseBlockTimeout
+//
+//	@Description: 解析block_timeout策略的超时时间
+//	@param s 如100ms，为空时返回DefaultBlockTimeout
+//	@return time.Duration
+//	@return error
+func parseBlockTimeout(s string) (time.Duration, error) {
+	if s == "" {
+		return DefaultBlockTimeout, nil
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil || duration <= 0 {
+		return 0, errors.New("invalid block_timeout " + strconv.Quote(s))
+	}
+	return duration, nil
+}
+
+// submit
+//
+//	@Description: 将日志消息体写入消息管道，由消费协程分发给各输出端格式化输出；管道已满时按策略处理，日志关闭后写到标准错误
+//	@receiver g
+//	@param entity
+func (g *GoLog) submit(entity *LogEntity) {
+	if !g.acquire() {
+		g.writeLate(entity)
+		return
+	}
+	defer g.release()
+	select {
+	case g.msgChan <- entity:
+		return
+	default:
+	}
+	c := g.config.Load()
+	switch c.overflowPolicy {
+	case OverflowBlockTimeout:
+		timer := time.NewTimer(c.blockTimeout)
+		defer timer.Stop()
+		select {
+		case g.msgChan <- entity:
+		case <-timer.C:
+			g.drop(entity)
+		}
+	case OverflowDropNewest:
+		g.drop(entity)
+	case OverflowDropOldest:
+		for {
+			select {
+			case g.msgChan <- entity:
+				return
+			default:
+			}
+			select {
+			case oldest := <-g.msgChan:
+				g.drop(oldest)
+			default:
+			}
+		}
+	case OverflowSyncWrite:
+		g.writeEntry(entity)
+		releaseEntity(entity)
+	default:
+		g.msgChan <- entity
+	}
+}
+
+// drop
+//
+//	@Description: 丢弃日志并计数
+//	@receiver g
+//	@param entity
+func (g *GoLog) drop(entity *LogEntity) {
+	releaseEntity(entity)
+	g.dropped.Add(1)
+}
+
+// Dropped
+//
+//	@Description: 获取因管道已满而丢弃的日志总数
+//	@receiver g
+//	@return uint64
+func (g *GoLog) Dropped() uint64 {
+	return g.dropped.Load()
+}
+
+// reportDropped
+//
+//	@Description: 管道排空后输出一条"N messages dropped"的Warn日志，两次输出至少间隔dropReportInterval，只能在消费协程中调用
+//	@receiver g
+func (g *GoLog) reportDropped() {
+	dropped := g.dropped.Load()
+	if dropped == g.reportedDropped || len(g.msgChan) > 0 || time.Since(g.lastDropReport) < dropReportInterval {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	entity := newEntity(LoglevelWarn, Fields{{Key: "dropped_total", Value: dropped}}, pcs[0])
+	entity.Msg = strconv.FormatUint(dropped-g.reportedDropped, 10) + " messages dropped"
+	g.reportedDropped = dropped
+	g.lastDropReport = time.Now()
+	g.writeEntry(entity)
+	releaseEntity(entity)
+}
@@ -0,0 +1,404 @@
+package go_log
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// PatternLayout
+// @Description: 编译后的转换模式（类似log4j的PatternLayout），如 "%d{2006-01-02 15:04:05.000} [%p] %c{short}:%L %F - %m%n"
+//
+//	支持的转换符：
+//	%d{layout} 日志时间，layout可以是Go的时间格式或者TimeLayOut常量名（如DefaultLayout、DateTimeLayout4），默认DefaultLayout
+//	%p 日志级别；%c{short|long} 产生日志的文件；%L 行号；%l 文件:行号；%F、%M 调用者函数名
+//	%m 日志内容；%X 所有结构化字段；%X{key} 指定字段的值；%n 换行；%% 百分号
+//	%highlight{...} 按日志级别着色；%red{...}、%green{...}等 使用指定颜色，颜色仅在开启彩色输出时生效
+//	宽度修饰：%5p 右对齐补齐到5个字符，%-5p 左对齐，%.20c 超出20个字符时截掉前面的部分
+type PatternLayout struct {
+	pattern string        //原始模式
+	nodes   []patternNode //编译后的节点
+}
+
+// patternNode
+// @Description: 模式中的一个节点，字面量或转换符
+type patternNode interface {
+	appendTo(buf []byte, entry *LogEntity, color bool) []byte
+}
+
+// literalNode
+// @Description: 原样输出的字面量
+type literalNode string
+
+func (n literalNode) appendTo(buf []byte, _ *LogEntity, _ bool) []byte {
+	return append(buf, n...)
+}
+
+// converterNode
+// @Description: 转换符节点，带有宽度修饰
+type converterNode struct {
+	convert   func(buf []byte, entry *LogEntity, color bool) []byte //转换函数
+	minWidth  int                                                   //最小宽度，不足时补空格
+	maxWidth  int                                                   //最大宽度，超出时截掉前面的部分，0表示不限制
+	leftAlign bool                                                  //是否左对齐
+}
+
+func (n *converterNode) appendTo(buf []byte, entry *LogEntity, color bool) []byte {
+	if n.minWidth == 0 && n.maxWidth == 0 {
+		return n.convert(buf, entry, color)
+	}
+	start := len(buf)
+	buf = n.convert(buf, entry, color)
+	value := buf[start:]
+	width := utf8.RuneCount(value)
+	if n.maxWidth > 0 && width > n.maxWidth {
+		for width > n.maxWidth {
+			_, size := utf8.DecodeRune(value)
+			value = value[size:]
+			width--
+		}
+		buf = append(buf[:start], value...)
+	}
+	if width >= n.minWidth {
+		return buf
+	}
+	padding := strings.Repeat(" ", n.minWidth-width)
+	if n.leftAlign {
+		return append(buf, padding...)
+	}
+	value = append([]byte(padding), buf[start:]...)
+	return append(buf[:start], value...)
+}
+
+// colorNode
+// @Description: 着色节点，颜色为空时按日志级别着色
+type colorNode struct {
+	color Color
+	nodes []patternNode
+}
+
+func (n *colorNode) appendTo(buf []byte, entry *LogEntity, color bool) []byte {
+	if color {
+		c := n.color
+		if c == "" {
+			c = entry.LogLevel.Color()
+		}
+		buf = append(buf, c...)
+	}
+	for _, node := range n.nodes {
+		buf = node.appendTo(buf, entry, color)
+	}
+	if color {
+		buf = append(buf, Reset...)
+	}
+	return buf
+}
+
+// namedLayouts 可以在%d{}中直接使用的时间格式名称
+var namedLayouts = map[string]TimeLayOut{
+	"DefaultLayout":   DefaultLayout,
+	"DateLayout":      DateLayout,
+	"TimeLayout":      TimeLayout,
+	"DateTimeLayout1": DateTimeLayout1,
+	"DateTimeLayout2": DateTimeLayout2,
+	"DateTimeLayout3": DateTimeLayout3,
+	"DateTimeLayout4": DateTimeLayout4,
+	"RFC3339":         time.RFC3339,
+	"RFC3339Nano":     time.RFC3339Nano,
+}
+
+// namedColors 可以作为着色转换符使用的颜色名称
+var namedColors = map[string]Color{
+	"red":     Red,
+	"green":   Green,
+	"yellow":  Yellow,
+	"blue":    Blue,
+	"magenta": Magenta,
+	"cyan":    Cyan,
+	"white":   White,
+}
+
+// CompilePattern
+//
+//	@Description: 编译转换模式，编译结果可以被多个日志复用
+//	@param pattern
+//	@return *PatternLayout
+//	@return error
+func CompilePattern(pattern string) (*PatternLayout, error) {
+	nodes, err := parsePattern(pattern)
+	if err != nil {
+		return nil, errors.New("invalid pattern " + strconv.Quote(pattern) + ": " + err.Error())
+	}
+	return &PatternLayout{pattern: pattern, nodes: nodes}, nil
+}
+
+// String
+//
+//	@Description: 获取原始模式
+//	@receiver p
+//	@return string
+func (p *PatternLayout) String() string {
+	return p.pattern
+}
+
+// Format
+//
+//	@Description: 按照模式格式化日志
+//	@receiver p
+//	@param entry
+//	@param color 是否输出颜色
+//	@return string
+func (p *PatternLayout) Format(entry *LogEntity, color bool) string {
+	bp := bufPool.Get().(*[]byte)
+	buf := (*bp)[:0]
+	for _, node := range p.nodes {
+		buf = node.appendTo(buf, entry, color)
+	}
+	s := string(buf)
+	putBuf(bp, buf)
+	return s
+}
+
+// Formatter
+//
+//	@Description: 转换为格式化器，可通过SetLogFormatter使用
+//	@receiver p
+//	@param color 是否输出颜色
+//	@return func(entry *LogEntity) string
+func (p *PatternLayout) Formatter(color bool) func(entry *LogEntity) string {
+	return func(entry *LogEntity) string {
+		return p.Format(entry, color)
+	}
+}
+
+// parsePattern
+//
+//	@Description: 解析模式为节点列表
+//	@param pattern
+//	@return []patternNode
+//	@return error
+func parsePattern(pattern string) ([]patternNode, error) {
+	var nodes []patternNode
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			nodes = append(nodes, literalNode(literal.String()))
+			literal.Reset()
+		}
+	}
+	for i := 0; i < len(pattern); {
+		if pattern[i] != '%' {
+			literal.WriteByte(pattern[i])
+			i++
+			continue
+		}
+		i++
+		if i >= len(pattern) {
+			return nil, errors.New("dangling % at end of pattern")
+		}
+		if pattern[i] == '%' {
+			literal.WriteByte('%')
+			i++
+			continue
+		}
+		// 宽度修饰
+		node := &converterNode{}
+		if pattern[i] == '-' {
+			node.leftAlign = true
+			i++
+		}
+		node.minWidth, i = parseNumber(pattern, i)
+		if i < len(pattern) && pattern[i] == '.' {
+			node.maxWidth, i = parseNumber(pattern, i+1)
+			if node.maxWidth == 0 {
+				return nil, errors.New("invalid max width at offset " + strconv.Itoa(i))
+			}
+		}
+		// 转换符名称
+		start := i
+		for i < len(pattern) && isLetter(pattern[i]) {
+			i++
+		}
+		name := pattern[start:i]
+		if name == "" {
+			return nil, errors.New("missing conversion name at offset " + strconv.Itoa(start))
+		}
+		if !isConverter(name) {
+			// 形如%mabc，只有第一个字母是转换符，其余为字面量
+			name, i = name[:1], start+1
+		}
+		// 转换符参数
+		var option string
+		hasOption := false
+		if i < len(pattern) && pattern[i] == '{' {
+			end, err := matchBrace(pattern, i)
+			if err != nil {
+				return nil, err
+			}
+			option, hasOption, i = pattern[i+1:end], true, end+1
+		}
+		flush()
+		if name == "highlight" || namedColors[name] != "" {
+			if !hasOption {
+				return nil, errors.New("%" + name + " requires a sub pattern")
+			}
+			children, err := parsePattern(option)
+			if err != nil {
+				return nil, err
+			}
+			// 宽度修饰作用于着色内的文本，颜色码不计入宽度
+			node.convert = func(buf []byte, entry *LogEntity, color bool) []byte {
+				for _, child := range children {
+					buf = child.appendTo(buf, entry, color)
+				}
+				return buf
+			}
+			nodes = append(nodes, &colorNode{color: namedColors[name], nodes: []patternNode{node}})
+			continue
+		}
+		convert, err := newConverter(name, option)
+		if err != nil {
+			return nil, err
+		}
+		node.convert = convert
+		nodes = append(nodes, node)
+	}
+	flush()
+	return nodes, nil
+}
+
+// newConverter
+//
+//	@Description: 根据名称及参数创建转换函数
+//	@param name
+//	@param option
+//	@return func(buf []byte, entry *LogEntity, color bool) []byte
+//	@return error
+func newConverter(name, option string) (func(buf []byte, entry *LogEntity, color bool) []byte, error) {
+	switch name {
+	case "d":
+		layout := string(DefaultLayout)
+		if option != "" {
+			layout = option
+			if named, ok := namedLayouts[option]; ok {
+				layout = string(named)
+			}
+		}
+		return func(buf []byte, entry *LogEntity, _ bool) []byte {
+			return entry.LogTime.AppendFormat(buf, layout)
+		}, nil
+	case "p":
+		return func(buf []byte, entry *LogEntity, _ bool) []byte {
+			return append(buf, entry.LogLevel...)
+		}, nil
+	case "c":
+		switch option {
+		case "", "long":
+			return func(buf []byte, entry *LogEntity, _ bool) []byte {
+				return append(buf, entry.LogFile...)
+			}, nil
+		case "short":
+			return func(buf []byte, entry *LogEntity, _ bool) []byte {
+				return append(buf, entry.LogFile[strings.LastIndexAny(entry.LogFile, `/\`)+1:]...)
+			}, nil
+		default:
+			return nil, errors.New("unknown option " + strconv.Quote(option) + " for %c")
+		}
+	case "L":
+		return func(buf []byte, entry *LogEntity, _ bool) []byte {
+			return strconv.AppendInt(buf, int64(entry.LineNum), 10)
+		}, nil
+	case "l":
+		return func(buf []byte, entry *LogEntity, _ bool) []byte {
+			buf = append(buf, entry.LogFile...)
+			buf = append(buf, ':')
+			return strconv.AppendInt(buf, int64(entry.LineNum), 10)
+		}, nil
+	case "F", "M":
+		return func(buf []byte, entry *LogEntity, _ bool) []byte {
+			return append(buf, entry.FuncName()...)
+		}, nil
+	case "m":
+		return func(buf []byte, entry *LogEntity, _ bool) []byte {
+			return append(buf, entry.Msg...)
+		}, nil
+	case "n":
+		return func(buf []byte, _ *LogEntity, _ bool) []byte {
+			return append(buf, '\n')
+		}, nil
+	case "X":
+		if option == "" {
+			return func(buf []byte, entry *LogEntity, _ bool) []byte {
+				return append(buf, entry.Fields.String()...)
+			}, nil
+		}
+		return func(buf []byte, entry *LogEntity, _ bool) []byte {
+			for i := len(entry.Fields) - 1; i >= 0; i-- {
+				if entry.Fields[i].Key == option {
+					return append(buf, fieldValueString(entry.Fields[i].Value)...)
+				}
+			}
+			return buf
+		}, nil
+	default:
+		return nil, errors.New("unknown conversion %" + name)
+	}
+}
+
+// isConverter
+//
+//	@Description: 判断是否为支持的转换符
+//	@param name
+//	@return bool
+func isConverter(name string) bool {
+	switch name {
+	case "d", "p", "c", "L", "l", "F", "M", "m", "n", "X", "highlight":
+		return true
+	}
+	return namedColors[name] != ""
+}
+
+// matchBrace
+//
+//	@Description: 查找与start处的左括号匹配的右括号
+//	@param pattern
+//	@param start
+//	@return int
+//	@return error
+func matchBrace(pattern string, start int) (int, error) {
+	depth := 0
+	for i := start; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, errors.New("unclosed { at offset " + strconv.Itoa(start))
+}
+
+// parseNumber
+//
+//	@Description: 解析从i开始的十进制数
+//	@param s
+//	@param i
+//	@return int 数值，没有数字时为0
+//	@return int 数字之后的位置
+func parseNumber(s string, i int) (int, int) {
+	n := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		n = n*10 + int(s[i]-'0')
+		i++
+	}
+	return n, i
+}
+
+func isLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
@@ -0,0 +1,345 @@
+package go_log
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 按日历对齐的滚动时间，可用于RollLogByTime
+const (
+	RollHourly  = "hourly"  //每小时整点滚动
+	RollDaily   = "daily"   //每天零点滚动
+	RollWeekly  = "weekly"  //每周一零点滚动
+	RollMonthly = "monthly" //每月一日零点滚动
+)
+
+// cronSearchLimit cron表达式查找下一个滚动时间的最大范围
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// rollSchedule
+// @Description: 滚动计划，时区由传入时间的Location决定
+type rollSchedule interface {
+	next(t time.Time) time.Time //t之后的第一个滚动时间，没有时返回零值
+	prev(t time.Time) time.Time //不晚于t的最后一个滚动时间，即t所在时间块的开始时间，没有时返回零值
+}
+
+// durationSchedule
+// @Description: 按固定间隔滚动。不超过一天的间隔按所在时区的钟表时间从每天零点开始计算，如5m在每个整5分钟滚动，
+// 不能整除一天时当天最后一个时间块到次日零点结束，夏令时切换时跳过不存在的钟表时间；整天数的间隔从1970-01-01起按日期对齐，
+// 如48h每两天零点滚动；其他超过一天的间隔按绝对时间对齐
+type durationSchedule time.Duration
+
+func (d durationSchedule) prev(t time.Time) time.Time {
+	duration := time.Duration(d)
+	year, month, day := t.Date()
+	switch {
+	case duration <= 24*time.Hour:
+		step := int(duration / time.Second)
+		clock := t.Hour()*3600 + t.Minute()*60 + t.Second()
+		return time.Date(year, month, day, 0, 0, clock-clock%step, 0, t.Location())
+	case duration%(24*time.Hour) == 0:
+		days := int64(duration / (24 * time.Hour))
+		epochDay := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / 86400
+		epochDay -= ((epochDay % days) + days) % days
+		return time.Date(1970, 1, 1+int(epochDay), 0, 0, 0, 0, t.Location())
+	default:
+		return t.Truncate(duration)
+	}
+}
+
+func (d durationSchedule) next(t time.Time) time.Time {
+	duration := time.Duration(d)
+	year, month, day := t.Date()
+	switch {
+	case duration <= 24*time.Hour:
+		step := int(duration / time.Second)
+		clock := t.Hour()*3600 + t.Minute()*60 + t.Second()
+		next := time.Date(year, month, day, 0, 0, clock-clock%step+step, 0, t.Location())
+		//  夏令时结束时重复的钟表时间取第一次出现的时刻，可能不晚于t
+		for !next.After(t) {
+			clock += step
+			next = time.Date(year, month, day, 0, 0, clock-clock%step+step, 0, t.Location())
+		}
+		if midnight := time.Date(year, month, day+1, 0, 0, 0, 0, t.Location()); next.After(midnight) {
+			return midnight
+		}
+		return next
+	case duration%(24*time.Hour) == 0:
+		year, month, day = d.prev(t).Date()
+		return time.Date(year, month, day+int(duration/(24*time.Hour)), 0, 0, 0, 0, t.Location())
+	default:
+		return t.Truncate(duration).Add(duration)
+	}
+}
+
+// calendarSchedule
+// @Description: 按日历滚动：每小时、每天、每周、每月
+type calendarSchedule string
+
+func (c calendarSchedule) prev(t time.Time) time.Time {
+	year, month, day := t.Date()
+	switch string(c) {
+	case RollHourly:
+		return time.Date(year, month, day, t.Hour(), 0, 0, 0, t.Location())
+	case RollDaily:
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	case RollWeekly:
+		return time.Date(year, month, day-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	}
+}
+
+func (c calendarSchedule) next(t time.Time) time.Time {
+	year, month, day := t.Date()
+	switch string(c) {
+	case RollHourly:
+		return time.Date(year, month, day, t.Hour()+1, 0, 0, 0, t.Location())
+	case RollDaily:
+		return time.Date(year, month, day+1, 0, 0, 0, 0, t.Location())
+	case RollWeekly:
+		return time.Date(year, month, day+7-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(year, month+1, 1, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// cronSchedule
+// @Description: 按cron表达式滚动，分 时 日 月 周 五个字段，日与周都不为*时满足其一即可
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64 //各字段允许的取值，按位表示
+	domAny, dowAny                bool   //日、周是否为*
+}
+
+func (c *cronSchedule) next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+	for t.Before(limit) {
+		year, month, day := t.Date()
+		if c.month&(1<<uint(month)) == 0 {
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.matchDay(t) {
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(year, month, day, t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c *cronSchedule) prev(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute)
+	limit := t.Add(-cronSearchLimit)
+	for !t.Before(limit) {
+		year, month, day := t.Date()
+		if c.month&(1<<uint(month)) == 0 {
+			t = time.Date(year, month, 1, 0, 0, 0, 0, loc).Add(-time.Minute)
+			continue
+		}
+		if !c.matchDay(t) {
+			t = time.Date(year, month, day, 0, 0, 0, 0, loc).Add(-time.Minute)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(year, month, day, t.Hour(), 0, 0, 0, loc).Add(-time.Minute)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(-time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchDay
+//
+//	@Description: 判断日期是否满足日、周字段
+//	@receiver c
+//	@param t
+//	@return bool
+func (c *cronSchedule) matchDay(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// parseRollSchedule
+//
+//	@Description: 解析滚动时间
+//	@param rollLogByTime 时间间隔如5m、1h；hourly、daily、weekly、monthly；@hourly等cron描述符；或cron表达式如"0 3 * * *"；为空表示不按时间滚动
+//	@return rollSchedule 不按时间滚动时为nil
+//	@return error
+func parseRollSchedule(rollLogByTime string) (rollSchedule, error) {
+	spec := strings.ToLower(strings.TrimSpace(rollLogByTime))
+	switch strings.TrimPrefix(spec, "@") {
+	case "":
+		return nil, nil
+	case RollHourly:
+		return calendarSchedule(RollHourly), nil
+	case RollDaily, "midnight":
+		return calendarSchedule(RollDaily), nil
+	case RollWeekly:
+		return calendarSchedule(RollWeekly), nil
+	case RollMonthly:
+		return calendarSchedule(RollMonthly), nil
+	}
+	if strings.ContainsRune(spec, ' ') {
+		return parseCron(spec)
+	}
+	duration, err := time.ParseDuration(spec)
+	if err != nil || duration < time.Second {
+		return nil, errors.New("Invalid time:" + rollLogByTime)
+	}
+	return durationSchedule(duration.Truncate(time.Second)), nil
+}
+
+// parseCron
+//
+//	@Description: 解析cron表达式，支持*、数字、a-b范围、/n步长及逗号分隔的列表，周日为0或7
+//	@param spec
+//	@return *cronSchedule
+//	@return error
+func parseCron(spec string) (*cronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.New("invalid cron expression " + strconv.Quote(spec) + ", want 5 fields")
+	}
+	c := &cronSchedule{domAny: fields[2] == "*", dowAny: fields[4] == "*"}
+	var err error
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	targets := [5]*uint64{&c.minute, &c.hour, &c.dom, &c.month, &c.dow}
+	for i, field := range fields {
+		if *targets[i], err = parseCronField(field, bounds[i][0], bounds[i][1]); err != nil {
+			return nil, errors.New("invalid cron expression " + strconv.Quote(spec) + ",err:" + err.Error())
+		}
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	if c.next(time.Now()).IsZero() {
+		return nil, errors.New("cron expression " + strconv.Quote(spec) + " never fires")
+	}
+	return c, nil
+}
+
+// parseCronField
+//
+//	@Description: 解析cron表达式的一个字段
+//	@param field
+//	@param min 最小值
+//	@param max 最大值
+//	@return uint64 允许的取值，按位表示
+//	@return error
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, errors.New("invalid step " + strconv.Quote(part))
+			}
+			rangePart = part[:i]
+		}
+		start, end := min, max
+		if rangePart != "*" {
+			var err error
+			bounds := strings.SplitN(rangePart, "-", 2)
+			if start, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, errors.New("invalid value " + strconv.Quote(part))
+			}
+			end = start
+			if len(bounds) == 2 {
+				if end, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, errors.New("invalid value " + strconv.Quote(part))
+				}
+			} else if step > 1 {
+				end = max
+			}
+		}
+		if start < min || end > max || start > end {
+			return 0, errors.New("value out of range " + strconv.Quote(part))
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// parseRollLocation
+//
+//	@Description: 解析滚动时间使用的时区
+//	@param name 如Asia/Shanghai、UTC，为空或Local时使用本地时区
+//	@return *time.Location
+//	@return error
+func parseRollLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errors.New("invalid roll_location " + strconv.Quote(name) + ",err:" + err.Error())
+	}
+	return loc, nil
+}
+
+// parseRollTimeLayout
+//
+//	@Description: 校验滚动文件名中时间块的格式
+//	@param layout 如2006010215，为空时使用DateTimeLayout4
+//	@return string
+//	@return error 格式化结果不能包含路径分隔符，且需要能解析回时间以便识别压缩文件
+func parseRollTimeLayout(layout string) (string, error) {
+	if layout == "" {
+		return string(DateTimeLayout4), nil
+	}
+	sample := time.Date(2023, 2, 28, 14, 55, 0, 0, time.UTC).Format(layout)
+	if sample == "" || strings.ContainsAny(sample, `/\`) {
+		return "", errors.New("invalid roll_time_layout " + strconv.Quote(layout))
+	}
+	if _, err := time.Parse(layout, sample); err != nil {
+		return "", errors.New("invalid roll_time_layout " + strconv.Quote(layout) + ",err:" + err.Error())
+	}
+	return layout, nil
+}
+
+// NextRollTime
+//
+//	@Description: 计算按时间滚动时t之后的第一个滚动时间
+//	@param rollLogByTime @See GoLogConfig.RollLogByTime
+//	@param t 时区取t的Location
+//	@return time.Time 不按时间滚动时为零值
+//	@return error
+func NextRollTime(rollLogByTime string, t time.Time) (time.Time, error) {
+	schedule, err := parseRollSchedule(rollLogByTime)
+	if err != nil || schedule == nil {
+		return time.Time{}, err
+	}
+	return schedule.next(t), nil
+}
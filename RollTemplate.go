@@ -0,0 +1,253 @@
+package go_log
+
+import (
+	"errors"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 滚动文件名模板中的片段类型
+const (
+	segmentLiteral = iota //原样输出的文本
+	segmentTime           //时间块
+	segmentIndex          //序号
+)
+
+// templateSegment
+// @Description: 滚动文件名模板的一个片段
+type templateSegment struct {
+	kind   int
+	text   string //segmentLiteral的文本
+	layout string //segmentTime的格式，为空时使用RollTimeLayout
+}
+
+// rollTemplate
+// @Description: 滚动文件名模板，如 {dir}/{name}-{time:20060102-1504}.{index}{ext}，解析后只包含文件名部分
+type rollTemplate struct {
+	text     string //原始模板
+	segments []templateSegment
+	hasTime  bool //是否包含{time}
+	hasIndex bool //是否包含{index}
+}
+
+// parseRollTemplate
+//
+//	@Description: 解析滚动文件名模板，支持{dir}（只能出现在开头）、{name}（LogName去掉扩展名）、{ext}（LogName的扩展名，如.log）、
+//	{time}、{time:layout}（时间块，默认格式为RollTimeLayout）、{index}（同一时间块内从1开始的序号）；模板至少包含{time}或{index}之一
+//	@param template
+//	@param logName 日志文件名
+//	@return *rollTemplate
+//	@return error
+func parseRollTemplate(template, logName string) (*rollTemplate, error) {
+	invalid := func(reason string) error {
+		return errors.New("invalid roll_name_template " + strconv.Quote(template) + ": " + reason)
+	}
+	ext := filepath.Ext(logName)
+	name := strings.TrimSuffix(filepath.Base(logName), ext)
+	rest := strings.TrimPrefix(strings.TrimPrefix(template, "{dir}/"), "{dir}"+string(filepath.Separator))
+	t := &rollTemplate{text: template}
+	literal := func(text string) {
+		if n := len(t.segments); n > 0 && t.segments[n-1].kind == segmentLiteral {
+			t.segments[n-1].text += text
+			return
+		}
+		t.segments = append(t.segments, templateSegment{kind: segmentLiteral, text: text})
+	}
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			literal(rest)
+			break
+		}
+		if open > 0 {
+			literal(rest[:open])
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, invalid("unclosed placeholder")
+		}
+		placeholder := rest[open+1 : open+end]
+		rest = rest[open+end+1:]
+		switch {
+		case placeholder == "name":
+			literal(name)
+		case placeholder == "ext":
+			literal(ext)
+		case placeholder == "index":
+			if t.hasIndex {
+				return nil, invalid("duplicate {index}")
+			}
+			t.hasIndex = true
+			t.segments = append(t.segments, templateSegment{kind: segmentIndex})
+		case placeholder == "time" || strings.HasPrefix(placeholder, "time:"):
+			layout := strings.TrimPrefix(strings.TrimPrefix(placeholder, "time"), ":")
+			if layout != "" {
+				if _, err := parseRollTimeLayout(layout); err != nil {
+					return nil, invalid(err.Error())
+				}
+			}
+			t.hasTime = true
+			t.segments = append(t.segments, templateSegment{kind: segmentTime, layout: layout})
+		default:
+			return nil, invalid("unknown placeholder {" + placeholder + "}")
+		}
+	}
+	for _, segment := range t.segments {
+		if segment.kind == segmentLiteral && strings.ContainsAny(segment.text, `/\{}`) {
+			return nil, invalid("rolled files must be in the log directory")
+		}
+	}
+	if !t.hasTime && !t.hasIndex {
+		return nil, invalid("{time} or {index} is required")
+	}
+	return t, nil
+}
+
+// newRollTemplate
+//
+//	@Description: 解析并校验滚动文件名模板
+//	@param template 为空时返回nil
+//	@param logName 日志文件名
+//	@param rollByTime 是否按时间滚动
+//	@param rollLogBySize 按大小滚动的大小
+//	@return *rollTemplate
+//	@return error
+func newRollTemplate(template, logName string, rollByTime bool, rollLogBySize int64) (*rollTemplate, error) {
+	if template == "" {
+		return nil, nil
+	}
+	t, err := parseRollTemplate(template, logName)
+	if err != nil {
+		return nil, err
+	}
+	if err = t.checkRoll(rollByTime, rollLogBySize); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// checkRoll
+//
+//	@Description: 校验模板与滚动配置是否匹配：按大小滚动时必须包含{index}；包含{time}时必须按时间滚动，否则时间块不会随时间变化
+//	@receiver t
+//	@param rollByTime 是否按时间滚动
+//	@param rollLogBySize 按大小滚动的大小
+//	@return error
+func (t *rollTemplate) checkRoll(rollByTime bool, rollLogBySize int64) error {
+	if rollLogBySize != 0 && !t.hasIndex {
+		return errors.New("roll_name_template " + strconv.Quote(t.text) + " requires {index} to roll by size")
+	}
+	if t.hasTime && !rollByTime {
+		return errors.New("roll_name_template " + strconv.Quote(t.text) + " requires roll_log_by_time to use {time}")
+	}
+	return nil
+}
+
+// render
+//
+//	@Description: 生成文件名
+//	@receiver t
+//	@param block 时间块的开始时间
+//	@param layout 默认的时间块格式
+//	@param index 序号
+//	@return string
+func (t *rollTemplate) render(block time.Time, layout string, index int64) string {
+	var b strings.Builder
+	for _, segment := range t.segments {
+		switch segment.kind {
+		case segmentLiteral:
+			b.WriteString(segment.text)
+		case segmentTime:
+			b.WriteString(block.Format(segment.layoutOr(layout)))
+		case segmentIndex:
+			b.WriteString(strconv.FormatInt(index, 10))
+		}
+	}
+	return b.String()
+}
+
+// regexp
+//
+//	@Description: 生成匹配文件名的正则表达式，{index}为名为index的分组，压缩文件的.zip后缀为名为zip的分组，
+//	block为nil时各时间块依次为未命名的分组
+//	@receiver t
+//	@param block 时间块的开始时间，为nil时匹配任意时间块
+//	@param layout 默认的时间块格式
+//	@return *regexp.Regexp
+func (t *rollTemplate) regexp(block *time.Time, layout string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteByte('^')
+	if !t.hasIndex {
+		b.WriteString("(?P<index>)")
+	}
+	for _, segment := range t.segments {
+		switch segment.kind {
+		case segmentLiteral:
+			b.WriteString(regexp.QuoteMeta(segment.text))
+		case segmentTime:
+			if block == nil {
+				b.WriteString("(.+?)")
+			} else {
+				b.WriteString(regexp.QuoteMeta(block.Format(segment.layoutOr(layout))))
+			}
+		case segmentIndex:
+			b.WriteString(`(?P<index>\d+)`)
+		}
+	}
+	b.WriteString(`(?P<zip>\.zip)?$`)
+	return regexp.MustCompile(b.String())
+}
+
+// archiveMatcher
+//
+//	@Description: 创建判断文件是否为按模板滚动后的压缩文件的函数，时间块需能按对应的格式解析，避免误判文件名前缀相同的其他日志的文件；正则只编译一次，可用于遍历整个目录
+//	@receiver t
+//	@param layout 默认的时间块格式
+//	@return func(name string) bool
+func (t *rollTemplate) archiveMatcher(layout string) func(name string) bool {
+	pattern := t.regexp(nil, layout)
+	zip := pattern.SubexpIndex("zip")
+	//  时间块在正则中是未命名的分组，按出现顺序对应模板中的时间段
+	var groups []int
+	var layouts []string
+	segments := t.segments
+	for group, name := range pattern.SubexpNames()[1:] {
+		if name != "" {
+			continue
+		}
+		for segments[0].kind != segmentTime {
+			segments = segments[1:]
+		}
+		groups = append(groups, group+1)
+		layouts = append(layouts, segments[0].layoutOr(layout))
+		segments = segments[1:]
+	}
+	return func(name string) bool {
+		match := pattern.FindStringSubmatch(name)
+		if match == nil || match[zip] == "" {
+			return false
+		}
+		for i, group := range groups {
+			if _, err := time.Parse(layouts[i], match[group]); err != nil {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// layoutOr
+//
+//	@Description: 时间块的格式
+//	@receiver s
+//	@param layout 片段未指定格式时使用的默认格式
+//	@return string
+func (s templateSegment) layoutOr(layout string) string {
+	if s.layout != "" {
+		return s.layout
+	}
+	return layout
+}
@@ -0,0 +1,31 @@
+//go:build !windows
+
+package go_log
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// reopenOnSignal
+//
+//	@Description: 收到SIGHUP信号时重新打开输出端，日志关闭后停止监听
+//	@receiver g
+func (g *GoLog) reopenOnSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-signals:
+				if err := g.Reopen(); err != nil {
+					_, _ = os.Stderr.WriteString("reopen log failed,err:" + err.Error() + "\n")
+				}
+			case <-g.closed:
+				return
+			}
+		}
+	}()
+}
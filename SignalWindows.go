@@ -0,0 +1,10 @@
+//go:build windows
+
+package go_log
+
+// reopenOnSignal
+//
+//	@Description: windows没有SIGHUP信号，不做处理
+//	@receiver g
+func (g *GoLog) reopenOnSignal() {
+}
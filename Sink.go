@@ -0,0 +1,210 @@
+package go_log
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// Sink
+// @Description: 日志输出端，一条日志会分发给日志的全部输出端，由各输出端按自己的级别、格式化器及颜色输出；
+// 输出端的方法只会在消费协程中调用
+type Sink interface {
+	// Enabled 是否输出该级别的日志
+	Enabled(level LogLevel) bool
+	// Write 输出一条日志，entry由对象池复用，返回后不能继续持有
+	Write(entry *LogEntity) error
+	// Sync 将缓冲的数据刷入底层存储
+	Sync() error
+	// Close 关闭输出端，日志销毁时调用
+	Close() error
+}
+
+// Reopener
+// @Description: 可以重新打开的输出端，如日志文件被外部工具移动或删除后重新打开 @See GoLog.Reopen
+type Reopener interface {
+	// Reopen 关闭并重新打开底层存储
+	Reopen() error
+}
+
+// SinkConfig
+// @Description: 输出端的公共配置
+type SinkConfig struct {
+	Level       LogLevel                      //最低日志级别，为空时不额外过滤；日志本身的级别是所有输出端的下限
+	ColorEnable bool                          //彩色输出，对默认的文本格式及转换模式生效
+	Formatter   func(entry *LogEntity) string //格式化器，为空时使用所属日志的格式（LogFormat、Pattern、SetLogFormatter）
+}
+
+// sinkBase
+// @Description: 输出端的级别、格式化器及颜色配置，可被并发修改
+type sinkBase struct {
+	sync.Mutex
+	level       LogLevel                                  //最低日志级别
+	colorEnable bool                                      //彩色输出
+	formatter   func(entry *LogEntity) string             //格式化器
+	inherit     func(entry *LogEntity, color bool) string //所属日志的格式，formatter为空时使用
+}
+
+// newSinkBase
+//
+//	@Description: 根据公共配置创建
+//	@param config
+//	@return sinkBase
+func newSinkBase(config SinkConfig) sinkBase {
+	return sinkBase{
+		level:       config.Level,
+		colorEnable: config.ColorEnable,
+		formatter:   config.Formatter,
+	}
+}
+
+// Enabled
+//
+//	@Description: 是否输出该级别的日志
+//	@receiver s
+//	@param level
+//	@return bool
+func (s *sinkBase) Enabled(level LogLevel) bool {
+	s.Lock()
+	defer s.Unlock()
+	return s.level == "" || s.level.LevelNum() <= level.LevelNum()
+}
+
+// SetLevel
+//
+//	@Description: 设置最低日志级别，为空时不额外过滤
+//	@receiver s
+//	@param level
+func (s *sinkBase) SetLevel(level LogLevel) {
+	s.Lock()
+	defer s.Unlock()
+	s.level = level
+}
+
+// SetColorEnable
+//
+//	@Description: 是否彩色输出
+//	@receiver s
+//	@param color
+func (s *sinkBase) SetColorEnable(color bool) {
+	s.Lock()
+	defer s.Unlock()
+	s.colorEnable = color
+}
+
+// SetFormatter
+//
+//	@Description: 设置格式化器，为空时使用所属日志的格式
+//	@receiver s
+//	@param f
+func (s *sinkBase) SetFormatter(f func(entry *LogEntity) string) {
+	s.Lock()
+	defer s.Unlock()
+	s.formatter = f
+}
+
+// inheritFormat
+//
+//	@Description: 添加到日志时设置所属日志的格式
+//	@receiver s
+//	@param format
+func (s *sinkBase) inheritFormat(format func(entry *LogEntity, color bool) string) {
+	s.Lock()
+	defer s.Unlock()
+	s.inherit = format
+}
+
+// format
+//
+//	@Description: 格式化日志，调用方需持有锁
+//	@receiver s
+//	@param entry
+//	@return string
+func (s *sinkBase) format(entry *LogEntity) string {
+	if s.formatter != nil {
+		return s.formatter(entry)
+	}
+	if s.inherit != nil {
+		return s.inherit(entry, s.colorEnable)
+	}
+	return formatMsg(entry, s.colorEnable)
+}
+
+// WriterSink
+// @Description: 输出到io.Writer的输出端，如控制台、网络连接；不负责关闭io.Writer
+type WriterSink struct {
+	sinkBase
+	writer io.Writer //输出流
+}
+
+// NewWriterSink
+//
+//	@Description: 创建输出到io.Writer的输出端
+//	@param writer
+//	@param config
+//	@return *WriterSink
+func NewWriterSink(writer io.Writer, config SinkConfig) *WriterSink {
+	return &WriterSink{sinkBase: newSinkBase(config), writer: writer}
+}
+
+// NewConsoleSink
+//
+//	@Description: 创建输出到标准输出的输出端
+//	@param config
+//	@return *WriterSink
+func NewConsoleSink(config SinkConfig) *WriterSink {
+	return NewWriterSink(os.Stdout, config)
+}
+
+// SetWriter
+//
+//	@Description: 替换输出流，为空时不输出
+//	@receiver s
+//	@param writer
+func (s *WriterSink) SetWriter(writer io.Writer) {
+	s.Lock()
+	defer s.Unlock()
+	s.writer = writer
+}
+
+// Write
+//
+//	@Description: 格式化并写出一条日志
+//	@receiver s
+//	@param entry
+//	@return error
+func (s *WriterSink) Write(entry *LogEntity) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.writer == nil {
+		return nil
+	}
+	_, err := io.WriteString(s.writer, s.format(entry))
+	return err
+}
+
+// Sync
+//
+//	@Description: 输出流实现了Sync() error时调用，标准输出、标准错误不做处理
+//	@receiver s
+//	@return error
+func (s *WriterSink) Sync() error {
+	s.Lock()
+	defer s.Unlock()
+	if s.writer == os.Stdout || s.writer == os.Stderr {
+		return nil
+	}
+	if syncer, ok := s.writer.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+// Close
+//
+//	@Description: 不关闭输出流，只做Sync @See WriterSink.Sync
+//	@receiver s
+//	@return error
+func (s *WriterSink) Close() error {
+	return s.Sync()
+}
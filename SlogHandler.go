@@ -0,0 +1,138 @@
+//go:build go1.21
+
+package go_log
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogHandler
+// @Description: slog.Handler 实现，将slog的日志记录写入GoLog，保留GoLog的异步管道、文件滚动与压缩
+type SlogHandler struct {
+	g      *GoLog //实际输出日志的GoLog
+	group  string //当前分组前缀，如"a.b."
+	fields Fields //通过WithAttrs绑定的字段
+}
+
+// NewSlogHandler
+//
+//	@Description: 创建一个基于GoLog的slog.Handler，如：slog.New(go_log.NewSlogHandler(g))
+//	@param g
+//	@return *SlogHandler
+func NewSlogHandler(g *GoLog) *SlogHandler {
+	return &SlogHandler{g: g}
+}
+
+// SlogLevel
+//
+//	@Description: 将slog的级别映射为GoLog的日志级别
+//	@param level
+//	@return LogLevel
+func SlogLevel(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelDebug:
+		return LoglevelTrace
+	case level < slog.LevelInfo:
+		return LoglevelDebug
+	case level < slog.LevelWarn:
+		return LoglevelInfo
+	case level < slog.LevelError:
+		return LoglevelWarn
+	default:
+		return LoglevelError
+	}
+}
+
+// Enabled
+//
+//	@Description: 判断指定级别的日志是否需要输出
+//	@receiver h
+//	@param _
+//	@param level
+//	@return bool
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.g.mayEnabled(SlogLevel(level))
+}
+
+// Handle
+//
+//	@Description: 将slog的日志记录转换为日志消息体写入GoLog，分组以"."连接作为字段名前缀
+//	@receiver h
+//	@param ctx
+//	@param record
+//	@return error
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if !h.g.enabledAt(SlogLevel(record.Level), record.PC) {
+		return nil
+	}
+	fields := make(Fields, 0, len(h.fields)+record.NumAttrs())
+	fields = append(fields, h.fields...)
+	fields = append(fields, extractContextFields(ctx)...)
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = appendAttr(fields, h.group, attr)
+		return true
+	})
+	entity := newEntity(SlogLevel(record.Level), h.g.bindFields(freezeFields(fields)), record.PC)
+	entity.Msg = record.Message
+	if !record.Time.IsZero() {
+		entity.LogTime = record.Time
+	}
+	h.g.submit(entity)
+	return nil
+}
+
+// WithAttrs
+//
+//	@Description: 返回绑定了字段的新Handler
+//	@receiver h
+//	@param attrs
+//	@return slog.Handler
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	fields := make(Fields, 0, len(h.fields)+len(attrs))
+	fields = append(fields, h.fields...)
+	for _, attr := range attrs {
+		fields = appendAttr(fields, h.group, attr)
+	}
+	return &SlogHandler{g: h.g, group: h.group, fields: freezeFields(fields)}
+}
+
+// WithGroup
+//
+//	@Description: 返回在指定分组下记录字段的新Handler
+//	@receiver h
+//	@param name
+//	@return slog.Handler
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{g: h.g, group: h.group + name + ".", fields: h.fields}
+}
+
+// appendAttr
+//
+//	@Description: 将slog属性转换为字段，分组属性展开为"group.key"形式，空属性会被忽略
+//	@param fields
+//	@param prefix
+//	@param attr
+//	@return Fields
+func appendAttr(fields Fields, prefix string, attr slog.Attr) Fields {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix = prefix + attr.Key + "."
+		}
+		for _, a := range attr.Value.Group() {
+			fields = appendAttr(fields, prefix, a)
+		}
+		return fields
+	}
+	return append(fields, Field{Key: prefix + attr.Key, Value: attr.Value.Any()})
+}
@@ -0,0 +1,441 @@
+package go_log
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SyslogFormat string //syslog消息格式
+
+const (
+	SyslogRFC5424 SyslogFormat = "rfc5424" //RFC 5424格式，字段输出为结构化数据，默认格式
+	SyslogRFC3164 SyslogFormat = "rfc3164" //RFC 3164（BSD）格式，字段以key=value附加在消息后
+)
+
+// Syslog facility
+const (
+	FacilityKern   = 0
+	FacilityUser   = 1
+	FacilityMail   = 2
+	FacilityDaemon = 3
+	FacilityAuth   = 4
+	FacilitySyslog = 5
+	FacilityLocal0 = 16
+	FacilityLocal1 = 17
+	FacilityLocal2 = 18
+	FacilityLocal3 = 19
+	FacilityLocal4 = 20
+	FacilityLocal5 = 21
+	FacilityLocal6 = 22
+	FacilityLocal7 = 23
+)
+
+// SyslogSDID RFC 5424结构化数据中字段所在的SD-ID
+const SyslogSDID = "fields@32473"
+
+// syslogSockets 未指定地址时依次尝试的本地syslog套接字
+var syslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogConfig
+// @Description: syslog输出端配置
+type SyslogConfig struct {
+	SinkConfig                 //Formatter用于生成MSG部分，为空时只输出日志内容；ColorEnable不生效
+	Network      string        //网络类型：udp、tcp、unix、unixgram，为空且Address为空时连接本地syslog套接字
+	Address      string        //地址，如127.0.0.1:514、/dev/log
+	Format       SyslogFormat  //消息格式，为空时使用rfc5424
+	Facility     int           //facility，1-23，为0时使用FacilityUser
+	AppName      string        //APP-NAME/TAG，为空时使用进程名
+	Hostname     string        //HOSTNAME，为空时使用os.Hostname
+	ProcID       string        //PROCID，为空时使用进程号
+	DialTimeout  time.Duration //连接超时，为0时为5秒
+	WriteTimeout time.Duration //写超时，为0时为5秒
+	MinBackoff   time.Duration //重连的初始间隔，每次失败翻倍，为0时为100毫秒
+	MaxBackoff   time.Duration //重连的最大间隔，为0时为30秒
+}
+
+// SyslogSink
+// @Description: 输出到syslog的输出端，TCP、unix等流式连接使用octet-counting分帧；写入失败后在后台按指数退避重连，
+// 断开期间的日志直接丢弃并计数
+type SyslogSink struct {
+	sinkBase
+	config   SyslogConfig
+	network  string   //实际使用的网络类型
+	address  string   //实际使用的地址
+	conn     net.Conn //连接，断开后为nil
+	facility int
+	backoff  time.Duration //当前的重连间隔
+	retry    *time.Timer   //断开期间的重连定时器
+	dialing  bool          //是否正在后台连接
+	dropped  uint64        //断开期间丢弃的日志条数
+	closed   bool
+}
+
+// NewSyslogSink
+//
+//	@Description: 创建syslog输出端并建立连接
+//	@param config
+//	@return *SyslogSink
+//	@return error 配置不合法或连接失败
+func NewSyslogSink(config SyslogConfig) (*SyslogSink, error) {
+	switch config.Format {
+	case "":
+		config.Format = SyslogRFC5424
+	case SyslogRFC5424, SyslogRFC3164:
+	default:
+		return nil, errors.New("unknown syslog format " + strconv.Quote(string(config.Format)))
+	}
+	if config.Facility == 0 {
+		config.Facility = FacilityUser
+	} else if config.Facility < 0 || config.Facility > FacilityLocal7 {
+		return nil, errors.New("invalid syslog facility " + strconv.Itoa(config.Facility))
+	}
+	if config.AppName == "" {
+		config.AppName = filepath.Base(os.Args[0])
+	}
+	if config.Hostname == "" {
+		config.Hostname, _ = os.Hostname()
+	}
+	if config.ProcID == "" {
+		config.ProcID = strconv.Itoa(os.Getpid())
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 5 * time.Second
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = 5 * time.Second
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = 100 * time.Millisecond
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 30 * time.Second
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = config.MinBackoff
+	}
+	s := &SyslogSink{sinkBase: newSinkBase(config.SinkConfig), config: config, facility: config.Facility}
+	conn, network, address, err := s.dial()
+	if err != nil {
+		return nil, err
+	}
+	s.conn, s.network, s.address = conn, network, address
+	return s, nil
+}
+
+// dial
+//
+//	@Description: 建立连接，未指定地址时依次尝试本地syslog套接字，不需要持有锁
+//	@receiver s
+//	@return net.Conn
+//	@return string 实际使用的网络类型
+//	@return string 实际使用的地址
+//	@return error
+func (s *SyslogSink) dial() (net.Conn, string, string, error) {
+	if s.config.Network != "" || s.config.Address != "" {
+		conn, err := net.DialTimeout(s.config.Network, s.config.Address, s.config.DialTimeout)
+		return conn, s.config.Network, s.config.Address, err
+	}
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, address := range syslogSockets {
+			conn, err := net.DialTimeout(network, address, s.config.DialTimeout)
+			if err == nil {
+				return conn, network, address, nil
+			}
+		}
+	}
+	return nil, "", "", errors.New("unix syslog delivery error")
+}
+
+// Write
+//
+//	@Description: 格式化并发送一条日志，发送失败时断开并在后台重连；断开期间丢弃日志，不在消费协程中建立连接
+//	@receiver s
+//	@param entry
+//	@return error 由连接状态变为断开时返回，断开期间丢弃日志不返回错误
+func (s *SyslogSink) Write(entry *LogEntity) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return errors.New("syslog sink " + s.address + " is closed")
+	}
+	if s.conn == nil {
+		s.dropped++
+		return nil
+	}
+	if err := s.write(entry); err != nil {
+		s.dropped++
+		s.fail()
+		return errors.New("syslog sink " + s.address + " disconnected,err:" + err.Error())
+	}
+	return nil
+}
+
+// Dropped
+//
+//	@Description: 获取断开期间丢弃的日志条数
+//	@receiver s
+//	@return uint64
+func (s *SyslogSink) Dropped() uint64 {
+	s.Lock()
+	defer s.Unlock()
+	return s.dropped
+}
+
+// write
+//
+//	@Description: 在写超时内发送一条日志，失败时关闭连接，调用方需持有锁
+//	@receiver s
+//	@param entry
+//	@return error
+func (s *SyslogSink) write(entry *LogEntity) error {
+	_ = s.conn.SetWriteDeadline(time.Now().Add(s.config.WriteTimeout))
+	if _, err := s.conn.Write(s.frame(s.message(entry))); err != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+// fail
+//
+//	@Description: 翻倍重连间隔并启动重连定时器，调用方需持有锁
+//	@receiver s
+func (s *SyslogSink) fail() {
+	if s.backoff == 0 {
+		s.backoff = s.config.MinBackoff
+	} else if s.backoff *= 2; s.backoff > s.config.MaxBackoff {
+		s.backoff = s.config.MaxBackoff
+	}
+	if s.retry == nil {
+		s.retry = time.AfterFunc(s.backoff, s.reconnect)
+	} else {
+		s.retry.Reset(s.backoff)
+	}
+}
+
+// reconnect
+//
+//	@Description: 重连定时器到期时在后台连接，连接期间不持有锁
+//	@receiver s
+func (s *SyslogSink) reconnect() {
+	s.Lock()
+	if s.closed || s.conn != nil || s.dialing {
+		s.Unlock()
+		return
+	}
+	s.dialing = true
+	s.Unlock()
+
+	conn, network, address, err := s.dial()
+
+	s.Lock()
+	defer s.Unlock()
+	s.dialing = false
+	if s.closed {
+		if conn != nil {
+			_ = conn.Close()
+		}
+		return
+	}
+	if err != nil {
+		s.fail()
+		return
+	}
+	s.conn, s.network, s.address = conn, network, address
+	s.backoff = 0
+}
+
+// Sync
+//
+//	@Description: 消息在Write时已发出，不做处理
+//	@receiver s
+//	@return error
+func (s *SyslogSink) Sync() error {
+	return nil
+}
+
+// Close
+//
+//	@Description: 关闭连接，停止重连
+//	@receiver s
+//	@return error
+func (s *SyslogSink) Close() error {
+	s.Lock()
+	defer s.Unlock()
+	s.closed = true
+	if s.retry != nil {
+		s.retry.Stop()
+	}
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// frame
+//
+//	@Description: 流式连接使用octet-counting分帧（RFC 6587），数据报连接原样发送
+//	@receiver s
+//	@param msg
+//	@return []byte
+func (s *SyslogSink) frame(msg []byte) []byte {
+	switch s.network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		buf := make([]byte, 0, len(msg)+8)
+		buf = strconv.AppendInt(buf, int64(len(msg)), 10)
+		buf = append(buf, ' ')
+		return append(buf, msg...)
+	default:
+		return msg
+	}
+}
+
+// message
+//
+//	@Description: 按配置的格式生成syslog消息
+//	@receiver s
+//	@param entry
+//	@return []byte
+func (s *SyslogSink) message(entry *LogEntity) []byte {
+	msg := entry.Msg
+	if s.formatter != nil {
+		msg = strings.TrimSuffix(s.formatter(entry), "\n")
+	}
+	buf := make([]byte, 0, 128+len(msg))
+	buf = append(buf, '<')
+	buf = strconv.AppendInt(buf, int64(s.facility*8+SyslogSeverity(entry.LogLevel)), 10)
+	buf = append(buf, '>')
+	if s.config.Format == SyslogRFC3164 {
+		buf = entry.LogTime.AppendFormat(buf, time.Stamp)
+		buf = append(buf, ' ')
+		buf = append(buf, syslogHeaderValue(s.config.Hostname, 255)...)
+		buf = append(buf, ' ')
+		buf = append(buf, syslogHeaderValue(s.config.AppName, 32)...)
+		buf = append(buf, '[')
+		buf = append(buf, syslogHeaderValue(s.config.ProcID, 128)...)
+		buf = append(buf, "]: "...)
+		buf = append(buf, msg...)
+		if len(entry.Fields) > 0 {
+			buf = append(buf, ' ')
+			buf = append(buf, entry.Fields.String()...)
+		}
+		return buf
+	}
+	buf = append(buf, "1 "...)
+	buf = entry.LogTime.AppendFormat(buf, "2006-01-02T15:04:05.000000Z07:00")
+	buf = append(buf, ' ')
+	buf = append(buf, syslogHeaderValue(s.config.Hostname, 255)...)
+	buf = append(buf, ' ')
+	buf = append(buf, syslogHeaderValue(s.config.AppName, 48)...)
+	buf = append(buf, ' ')
+	buf = append(buf, syslogHeaderValue(s.config.ProcID, 128)...)
+	buf = append(buf, " - "...)
+	buf = appendStructuredData(buf, entry.Fields)
+	if msg != "" {
+		buf = append(buf, ' ')
+		buf = append(buf, msg...)
+	}
+	return buf
+}
+
+// SyslogSeverity
+//
+//	@Description: 将日志级别映射为syslog severity：TRACE、DEBUG为7(debug)，INFO为6(info)，WARN为4(warning)，
+//	ERROR为3(err)，PANIC为2(crit)，FATAL为1(alert)，自定义级别按严重程度映射
+//	@param level
+//	@return int
+func SyslogSeverity(level LogLevel) int {
+	switch n := level.LevelNum(); {
+	case n <= LoglevelDebug.LevelNum():
+		return 7
+	case n == LoglevelInfo.LevelNum():
+		return 6
+	case n == LoglevelWarn.LevelNum():
+		return 4
+	case n == LoglevelError.LevelNum():
+		return 3
+	case n == LoglevelPanic.LevelNum():
+		return 2
+	default:
+		return 1
+	}
+}
+
+// syslogHeaderValue
+//
+//	@Description: 头部字段只能是可打印的ASCII字符，为空时为"-"
+//	@param s
+//	@param maxLen 最大长度
+//	@return string
+func syslogHeaderValue(s string, maxLen int) string {
+	if s == "" {
+		return "-"
+	}
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s) && len(b) < maxLen; i++ {
+		if s[i] > ' ' && s[i] < 127 {
+			b = append(b, s[i])
+		} else {
+			b = append(b, '_')
+		}
+	}
+	return string(b)
+}
+
+// appendStructuredData
+//
+//	@Description: 将字段输出为RFC 5424结构化数据，如 [fields@32473 user_id="42"]，没有字段时为"-"
+//	@param buf
+//	@param fields
+//	@return []byte
+func appendStructuredData(buf []byte, fields Fields) []byte {
+	if len(fields) == 0 {
+		return append(buf, '-')
+	}
+	buf = append(buf, '[')
+	buf = append(buf, SyslogSDID...)
+	for _, field := range fields {
+		buf = append(buf, ' ')
+		buf = appendSDName(buf, field.Key)
+		buf = append(buf, '=', '"')
+		value := fieldValueString(field.Value)
+		for i := 0; i < len(value); i++ {
+			switch value[i] {
+			case '"', '\\', ']':
+				buf = append(buf, '\\')
+			}
+			buf = append(buf, value[i])
+		}
+		buf = append(buf, '"')
+	}
+	return append(buf, ']')
+}
+
+// appendSDName
+//
+//	@Description: PARAM-NAME只能是除'='、' '、']'、'"'外的可打印ASCII字符，最长32个字符，不合法的字符替换为'_'
+//	@param buf
+//	@param name
+//	@return []byte
+func appendSDName(buf []byte, name string) []byte {
+	if name == "" {
+		return append(buf, '_')
+	}
+	for i := 0; i < len(name) && i < 32; i++ {
+		switch c := name[i]; {
+		case c <= ' ' || c >= 127 || c == '=' || c == ']' || c == '"':
+			buf = append(buf, '_')
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
@@ -0,0 +1,177 @@
+package go_log
+
+import (
+	"errors"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// vmoduleRule
+// @Description: 单条按文件/包覆盖日志级别的规则
+type vmoduleRule struct {
+	pattern   string //文件或包路径的glob
+	segments  int    //pattern中的路径段数
+	threshold int8   //覆盖后的日志级别（严重程度）
+}
+
+// vmodule
+// @Description: 按调用者文件或包路径覆盖日志级别（类似glog的--vmodule），匹配结果按调用点缓存
+type vmodule struct {
+	spec  string        //原始配置
+	rules []vmoduleRule //规则，按配置顺序匹配，第一个匹配的生效
+	cache sync.Map      //调用点pc -> int8，不匹配任何规则时为-1
+	min   int8          //所有规则中最低的日志级别
+}
+
+// parseVModule
+//
+//	@Description: 解析形如 "rotation*=DEBUG,github.com/foo/bar/*=TRACE,net/http=4" 的配置；
+//	不包含"/"的pattern匹配不带.go后缀的文件名，包含"/"的pattern匹配文件路径（不带.go后缀）、所在目录、包导入路径的末尾若干段，
+//	级别可以是日志级别名称或者严重程度数值
+//	@param spec
+//	@return *vmodule 配置为空时返回nil
+//	@return error
+func parseVModule(spec string) (*vmodule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	vm := &vmodule{spec: spec, min: -1}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		idx := strings.LastIndexByte(item, '=')
+		if idx <= 0 {
+			return nil, errors.New("invalid vmodule " + strconv.Quote(item) + ", want pattern=level")
+		}
+		pattern := strings.TrimSuffix(strings.TrimSpace(item[:idx]), ".go")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.New("invalid vmodule pattern " + strconv.Quote(pattern) + ": " + err.Error())
+		}
+		threshold, err := parseThreshold(strings.TrimSpace(item[idx+1:]))
+		if err != nil {
+			return nil, err
+		}
+		vm.rules = append(vm.rules, vmoduleRule{
+			pattern:   pattern,
+			segments:  strings.Count(pattern, "/") + 1,
+			threshold: threshold,
+		})
+		if vm.min < 0 || threshold < vm.min {
+			vm.min = threshold
+		}
+	}
+	if len(vm.rules) == 0 {
+		return nil, nil
+	}
+	return vm, nil
+}
+
+// parseThreshold
+//
+//	@Description: 解析日志级别名称或严重程度数值
+//	@param s
+//	@return int8
+//	@return error
+func parseThreshold(s string) (int8, error) {
+	if n, err := strconv.ParseInt(s, 10, 8); err == nil {
+		if n < 0 {
+			return 0, errors.New("invalid vmodule level " + s)
+		}
+		return int8(n), nil
+	}
+	level, err := ParseLevel(s)
+	if err != nil {
+		return 0, err
+	}
+	return level.LevelNum(), nil
+}
+
+// threshold
+//
+//	@Description: 获取调用点的日志级别
+//	@receiver vm
+//	@param pc 调用点
+//	@param def 没有匹配的规则时使用的日志级别
+//	@return int8
+func (vm *vmodule) threshold(pc uintptr, def int8) int8 {
+	if v, ok := vm.cache.Load(pc); ok {
+		if t := v.(int8); t >= 0 {
+			return t
+		}
+		return def
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	t := vm.match(frame.File, funcPackage(frame.Function))
+	vm.cache.Store(pc, t)
+	if t >= 0 {
+		return t
+	}
+	return def
+}
+
+// match
+//
+//	@Description: 按顺序匹配规则
+//	@receiver vm
+//	@param file 文件绝对路径
+//	@param pkg 文件所在包的导入路径
+//	@return int8 匹配的日志级别，不匹配时返回-1
+func (vm *vmodule) match(file, pkg string) int8 {
+	file = strings.TrimSuffix(strings.ReplaceAll(file, "\\", "/"), ".go")
+	base := path.Base(file)
+	candidates := []string{file, path.Dir(file)}
+	if pkg != "" {
+		candidates = append(candidates, pkg+"/"+base, pkg)
+	}
+	for _, rule := range vm.rules {
+		if rule.segments == 1 {
+			if ok, _ := path.Match(rule.pattern, base); ok {
+				return rule.threshold
+			}
+			continue
+		}
+		for _, candidate := range candidates {
+			if ok, _ := path.Match(rule.pattern, lastSegments(candidate, rule.segments)); ok {
+				return rule.threshold
+			}
+		}
+	}
+	return -1
+}
+
+// funcPackage
+//
+//	@Description: 从函数全名中获取包的导入路径，如 github.com/foo/bar.(*T).Method 返回 github.com/foo/bar
+//	@param function
+//	@return string
+func funcPackage(function string) string {
+	slash := strings.LastIndexByte(function, '/')
+	dot := strings.IndexByte(function[slash+1:], '.')
+	if dot < 0 {
+		return ""
+	}
+	return function[:slash+1+dot]
+}
+
+// lastSegments
+//
+//	@Description: 获取路径的末尾n段
+//	@param p
+//	@param n
+//	@return string
+func lastSegments(p string, n int) string {
+	idx := len(p)
+	for i := 0; i < n; i++ {
+		idx = strings.LastIndexByte(p[:idx], '/')
+		if idx < 0 {
+			return p
+		}
+	}
+	return p[idx+1:]
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"log"
+	"strings"
+	"testing"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// discardWriter
+// @Description: 丢弃全部内容的输出流，标准库log遇到io.Discard会跳过格式化，因此不能直接使用io.Discard
+type discardWriter struct{}
+
+func (discardWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// benchBufferSize 基准测试使用的管道长度
+const benchBufferSize = 4096
+
+// newDiscardLogger
+//
+//	@Description: 创建一个丢弃全部输出的日志
+//	@return *go_log.GoLog
+func newDiscardLogger() *go_log.GoLog {
+	logger, err := (&go_log.GoLogConfig{
+		LogLevel:       go_log.LoglevelInfo,
+		ShortLogEnable: true,
+		BufferSize:     benchBufferSize,
+		Writer:         discardWriter{},
+	}).Build()
+	if err != nil {
+		panic(err)
+	}
+	return logger
+}
+
+// TestDeferredFormat
+//
+//	@Description: 可变参数在调用时格式化，调用返回后的修改不影响日志内容
+//	@param t
+func TestDeferredFormat(t *testing.T) {
+	logger, buf := newBufferLogger(go_log.LoglevelInfo)
+	values := []int{1, 2}
+	logger.Info("name=%s age=%d values=%v", "二狗子", 18, values)
+	values[0] = 100
+	logger.Info("100%%")
+	logger.Destroy()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "name=二狗子 age=18 values=[1 2]") || !strings.HasSuffix(lines[1], "100%") {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+	if !strings.Contains(lines[0], "bench_test.go:") {
+		t.Fatalf("caller should be resolved: %q", lines[0])
+	}
+}
+
+// BenchmarkGoLog 对应TestDemo7，只衡量调用方的开销：每写满半个管道暂停计时等待消费协程写完，调用方不会因管道写满而阻塞
+func BenchmarkGoLog(b *testing.B) {
+	logger := newDiscardLogger()
+	defer logger.Destroy()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if i > 0 && i%(benchBufferSize/2) == 0 {
+			b.StopTimer()
+			logger.Flush()
+			b.StartTimer()
+		}
+		logger.Info("我的名字叫%s,我今年%d岁了", "二狗子", i)
+	}
+}
+
+// BenchmarkGoLogThroughput 包括消费协程格式化、输出在内的吞吐量，管道写满时调用方等待消费协程
+func BenchmarkGoLogThroughput(b *testing.B) {
+	logger := newDiscardLogger()
+	defer logger.Destroy()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info("我的名字叫%s,我今年%d岁了", "二狗子", i)
+	}
+	logger.Flush()
+}
+
+// BenchmarkGoLogParallel 多协程并发写日志的吞吐量，包括消费协程的开销
+func BenchmarkGoLogParallel(b *testing.B) {
+	logger := newDiscardLogger()
+	defer logger.Destroy()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			logger.Info("我的名字叫%s,我今年%d岁了", "二狗子", i)
+			i++
+		}
+	})
+	logger.Flush()
+}
+
+// BenchmarkStdLog 对应TestDemo8，标准库log同步格式化输出
+func BenchmarkStdLog(b *testing.B) {
+	logger := log.New(discardWriter{}, "", log.Lshortfile|log.Ldate|log.Ltime)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Printf("我的名字叫%s,我今年%d岁了", "二狗子", i)
+	}
+}
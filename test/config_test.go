@@ -0,0 +1,162 @@
+package test
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// TestLoadConfig
+//
+//	@Description: 从json、yaml文件中读取配置
+//	@param t
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "log.json")
+	_ = os.WriteFile(jsonPath, []byte(`{"log_level":"warn","buffer_size":16,"log_format":"json","json_format":{"msg_key":"message"}}`), 0644)
+	yamlPath := filepath.Join(dir, "log.yaml")
+	_ = os.WriteFile(yamlPath, []byte(`
+log_level: Debug
+short_log_enable: true
+buffer_size: 64
+pattern: "[%p] %m%n"
+roll_log_by_size: 1024
+`), 0644)
+
+	config, err := go_log.ReadConfig(jsonPath)
+	if err != nil {
+		t.Fatalf("read config failed,err:%v", err)
+	}
+	if config.LogLevel != go_log.LoglevelWarn || config.BufferSize != 16 || config.JsonFormat.MsgKey != "message" {
+		t.Fatalf("unexpected config: %+v", config)
+	}
+	config, err = go_log.ReadConfig(yamlPath)
+	if err != nil {
+		t.Fatalf("read config failed,err:%v", err)
+	}
+	if config.LogLevel != go_log.LoglevelDebug || !config.ShortLogEnable || config.RollLogBySize != 1024 {
+		t.Fatalf("unexpected config: %+v", config)
+	}
+
+	buf := &syncBuffer{}
+	logger, err := go_log.LoadConfig(yamlPath)
+	if err != nil {
+		t.Fatalf("load config failed,err:%v", err)
+	}
+	logger.SetLohWriter(buf)
+	logger.Debug("hello")
+	logger.Destroy()
+	if buf.String() != "[DEBUG] hello\n" {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+
+	for _, content := range []string{
+		`{"log_level":"verbose"}`,
+		`{"roll_log_by_time":"5x"}`,
+		`{"log_format":"xml"}`,
+		`{"pattern":"%q"}`,
+	} {
+		_ = os.WriteFile(jsonPath, []byte(content), 0644)
+		if _, err := go_log.LoadConfig(jsonPath); err == nil {
+			t.Fatalf("config %s should be invalid", content)
+		}
+	}
+}
+
+// TestWatchConfig
+//
+//	@Description: 配置文件变化时热更新日志级别与格式
+//	@param t
+func TestWatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.json")
+	_ = os.WriteFile(path, []byte(`{"log_level":"info","buffer_size":16}`), 0644)
+	logger, err := go_log.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("load config failed,err:%v", err)
+	}
+	defer logger.Destroy()
+	buf := &syncBuffer{}
+	logger.SetLohWriter(buf)
+	stop, err := logger.WatchConfig(path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("watch config failed,err:%v", err)
+	}
+	defer stop()
+
+	_ = os.WriteFile(path, []byte(`{"log_level":"debug","buffer_size":16,"log_format":"logfmt"}`), 0644)
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(buf.String(), "level=DEBUG") {
+		if time.Now().After(deadline) {
+			t.Fatalf("config should be reloaded: %q", buf.String())
+		}
+		logger.Debug("reloaded")
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestConfigFromEnv
+//
+//	@Description: 通过环境变量配置日志
+//	@param t
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("GOLOG_LEVEL", "error")
+	t.Setenv("GOLOG_DIR", "/var/log/app")
+	t.Setenv("GOLOG_ROLL_BY_SIZE", "2048")
+	t.Setenv("GOLOG_ROLL_BY_TIME", "1h")
+	t.Setenv("GOLOG_COLOR", "false")
+	config, err := go_log.ConfigFromEnv("GOLOG")
+	if err != nil {
+		t.Fatalf("config from env failed,err:%v", err)
+	}
+	if config.LogLevel != go_log.LoglevelError || config.LogDir != "/var/log/app" || config.RollLogBySize != 2048 ||
+		config.RollLogByTime != "1h" || config.ColorEnable || !config.ConsoleEnable || config.BufferSize != 256 {
+		t.Fatalf("unexpected config: %+v", config)
+	}
+
+	for key, value := range map[string]string{
+		"GOLOG_LEVEL":        "loud",
+		"GOLOG_ROLL_BY_TIME": "soon",
+		"GOLOG_ROLL_BY_SIZE": "big",
+		"GOLOG_FORMAT":       "xml",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			if _, err := go_log.ConfigFromEnv("GOLOG_"); err == nil || !strings.Contains(err.Error(), key) {
+				t.Fatalf("%s=%s should be invalid, err:%v", key, value, err)
+			}
+		})
+	}
+}
+
+// TestRegisterFlags
+//
+//	@Description: 通过命令行参数配置日志
+//	@param t
+func TestRegisterFlags(t *testing.T) {
+	config := go_log.DefaultConfig()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	config.RegisterFlags(fs)
+	err := fs.Parse([]string{"-log-level=debug", "-log-roll-by-time=5m", "-log-format=JSON", "-log-console=false"})
+	if err != nil {
+		t.Fatalf("parse flags failed,err:%v", err)
+	}
+	if config.LogLevel != go_log.LoglevelDebug || config.RollLogByTime != "5m" || config.LogFormat != go_log.LogFormatJson || config.ConsoleEnable {
+		t.Fatalf("unexpected config: %+v", config)
+	}
+	if err := fs.Parse([]string{"-log-roll-by-time=0.5s"}); err == nil {
+		t.Fatalf("invalid roll time should fail")
+	}
+	if err := fs.Parse([]string{"-log-level=loud"}); err == nil {
+		t.Fatalf("invalid level should fail")
+	}
+	if _, err := (&go_log.GoLogConfig{RollLogByTime: "5x"}).Build(); err == nil {
+		t.Fatalf("build should return error instead of panic")
+	}
+}
@@ -0,0 +1,230 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// syncBuffer
+// @Description: 并发安全的输出流，用于在测试中收集日志
+type syncBuffer struct {
+	sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.String()
+}
+
+// newBufferLogger
+//
+//	@Description: 创建一个只输出到内存的日志
+//	@param level
+//	@return go_log.ILogger
+//	@return *syncBuffer
+func newBufferLogger(level go_log.LogLevel) (go_log.ILogger, *syncBuffer) {
+	buf := &syncBuffer{}
+	logger := go_log.NewGoLog(&go_log.GoLogConfig{
+		LogLevel:       level,
+		ShortLogEnable: true,
+		MsgChan:        make(chan string, 256),
+	})
+	logger.SetLohWriter(buf)
+	return logger, buf
+}
+
+// TestFields
+//
+//	@Description: 结构化字段输出
+//	@param t
+func TestFields(t *testing.T) {
+	logger, buf := newBufferLogger(go_log.LoglevelDebug)
+	logger.Infow("order created", "user_id", 42, "order_id", "A 1")
+	logger.Debugw("dangling", "key")
+	logger.Tracew("filtered", "user_id", 1)
+	logger.Destroy()
+
+	out := buf.String()
+	if !strings.Contains(out, `order created user_id=42 order_id="A 1"`) {
+		t.Fatalf("unexpected output: %q", out)
+	}
+	if !strings.Contains(out, `dangling key=!MISSING`) {
+		t.Fatalf("unexpected output: %q", out)
+	}
+	if strings.Contains(out, "filtered") {
+		t.Fatalf("trace log should be filtered: %q", out)
+	}
+}
+
+// TestFieldsFormatter
+//
+//	@Description: 自定义格式化器可以直接拿到字段
+//	@param t
+func TestFieldsFormatter(t *testing.T) {
+	logger, buf := newBufferLogger(go_log.LoglevelInfo)
+	logger.SetLogFormatter(logFormatter)
+	logger.Infow("hello", "b", 1, "a", "x")
+	logger.Destroy()
+
+	var entity struct {
+		Msg    string
+		Fields map[string]any
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &entity); err != nil {
+		t.Fatalf("unmarshal %q failed,err:%v", buf.String(), err)
+	}
+	if entity.Msg != "hello" || entity.Fields["a"] != "x" || entity.Fields["b"] != float64(1) {
+		t.Fatalf("unexpected entity: %+v", entity)
+	}
+	bytes, _ := json.Marshal(go_log.Fields{{Key: "b", Value: 1}, {Key: "a", Value: "x"}})
+	if string(bytes) != `{"b":1,"a":"x"}` {
+		t.Fatalf("fields should keep order: %s", bytes)
+	}
+}
+
+// TestWith
+//
+//	@Description: 子日志共享父日志的配置并附加绑定的字段
+//	@param t
+func TestWith(t *testing.T) {
+	logger, buf := newBufferLogger(go_log.LoglevelInfo)
+	child := logger.With("request_id", "r1")
+	grandchild := child.With("user_id", 7)
+	child.Info("from child")
+	grandchild.Infow("from grandchild", "step", 2)
+	child.Destroy() //子日志不会销毁父日志
+	logger.SetLogLevel(go_log.LoglevelWarn)
+	grandchild.Info("filtered by parent level")
+	logger.Warn("from parent")
+	logger.Destroy()
+
+	out := buf.String()
+	for _, want := range []string{
+		"from child request_id=r1\n",
+		"from grandchild request_id=r1 user_id=7 step=2\n",
+		"from parent\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in output: %q", want, out)
+		}
+	}
+	if strings.Contains(out, "filtered by parent level") {
+		t.Fatalf("child should share the parent level: %q", out)
+	}
+}
+
+type traceIdKey struct{}
+
+// registerTraceId 进程内只注册一次trace_id提取器，-count大于1时重复运行的测试不会重复输出字段
+var registerTraceId sync.Once
+
+// TestContext
+//
+//	@Description: 从context中提取字段，并通过context传递日志
+//	@param t
+func TestContext(t *testing.T) {
+	registerTraceId.Do(func() {
+		go_log.RegisterContextExtractor(go_log.ContextValueExtractor(traceIdKey{}, "trace_id"))
+	})
+	logger, buf := newBufferLogger(go_log.LoglevelInfo)
+	ctx := context.WithValue(context.Background(), traceIdKey{}, "t-1")
+	ctx = go_log.NewContext(ctx, logger.With("tenant", "acme"))
+
+	go_log.FromContext(ctx).InfoCtx(ctx, "hello %s", "ctx")
+	logger.InfoCtx(context.Background(), "no trace")
+	logger.Destroy()
+
+	out := buf.String()
+	if !strings.Contains(out, "hello ctx tenant=acme trace_id=t-1\n") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+	if !strings.Contains(out, "no trace\n") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+	if go_log.FromContext(context.Background()) != go_log.GetSingleGoLog() {
+		t.Fatalf("FromContext should fall back to the single GoLog")
+	}
+}
+
+// TestUnregisterContextExtractor
+//
+//	@Description: 取消注册后提取器不再生效，重复取消不影响其他提取器
+//	@param t
+func TestUnregisterContextExtractor(t *testing.T) {
+	type tenantKey struct{}
+	unregister := go_log.RegisterContextExtractor(go_log.ContextValueExtractor(tenantKey{}, "tenant_id"))
+	logger, buf := newBufferLogger(go_log.LoglevelInfo)
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	logger.InfoCtx(ctx, "registered")
+	logger.Flush()
+	unregister()
+	unregister()
+	logger.InfoCtx(ctx, "unregistered")
+	logger.Destroy()
+
+	out := buf.String()
+	if !strings.Contains(out, "registered tenant_id=acme\n") || !strings.Contains(out, "unregistered\n") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+// TestFieldsMutation
+//
+//	@Description: 输出后立即修改作为字段值的map、切片，输出的是调用时的内容，json格式保留字段的结构，需配合-race运行
+//	@param t
+func TestFieldsMutation(t *testing.T) {
+	logger, buf := newBufferLogger(go_log.LoglevelDebug)
+	m := map[string]int{"n": 0}
+	child := logger.With("bound", m)
+	for i := 0; i < 100; i++ {
+		logger.Infow("mutation", "m", m)
+		child.Info("bound")
+		m["n"]++
+		m[strings.Repeat("k", i%5+1)] = i
+	}
+	logger.Destroy()
+	out := buf.String()
+	if !strings.Contains(out, `m="{\"n\":0}"`) || !strings.Contains(out, `bound="{\"n\":0}"`) {
+		t.Fatalf("field values should be copied when logged: %s", out)
+	}
+
+	jsonBuf := &syncBuffer{}
+	jsonLogger := go_log.NewGoLog(&go_log.GoLogConfig{
+		LogLevel:  go_log.LoglevelInfo,
+		MsgChan:   make(chan string, 256),
+		LogFormat: go_log.LogFormatJson,
+	})
+	jsonLogger.SetLohWriter(jsonBuf)
+	tags := []string{"a", "b"}
+	m = map[string]int{"x": 1}
+	jsonLogger.Infow("hello", "tags", tags, "m", m)
+	tags[0] = "changed"
+	m["x"] = 2
+	jsonLogger.Destroy()
+	var entity struct {
+		Fields struct {
+			Tags []string       `json:"tags"`
+			M    map[string]int `json:"m"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(jsonBuf.String()), &entity); err != nil {
+		t.Fatalf("unmarshal %q failed,err:%v", jsonBuf.String(), err)
+	}
+	if len(entity.Fields.Tags) != 2 || entity.Fields.Tags[0] != "a" || entity.Fields.M["x"] != 1 {
+		t.Fatalf("unexpected fields: %s", jsonBuf.String())
+	}
+}
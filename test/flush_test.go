@@ -0,0 +1,68 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// TestFlushSync
+//
+//	@Description: Flush、Sync后调用前的日志已写入文件，日志仍然可用
+//	@param t
+func TestFlushSync(t *testing.T) {
+	dir := t.TempDir()
+	logger := go_log.NewGoLog(&go_log.GoLogConfig{
+		LogLevel:       go_log.LoglevelInfo,
+		ShortLogEnable: true,
+		BufferSize:     1024,
+		LogDir:         dir,
+		LogName:        "flush.log",
+	})
+	defer logger.Destroy()
+	path := filepath.Join(dir, "flush.log")
+	count := func() int {
+		data, _ := os.ReadFile(path)
+		return strings.Count(string(data), "\n")
+	}
+
+	for i := 0; i < 500; i++ {
+		logger.Info("msg %d", i)
+	}
+	logger.Flush()
+	if n := count(); n != 500 {
+		t.Fatalf("want 500 lines after Flush, got %d", n)
+	}
+
+	child := logger.With("k", "v")
+	for i := 0; i < 500; i++ {
+		child.Info("child " + strconv.Itoa(i))
+	}
+	if err := child.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 1000 {
+		t.Fatalf("want 1000 lines after Sync, got %d", n)
+	}
+}
+
+// TestFlushAfterDestroy
+//
+//	@Description: 销毁后调用Flush、Sync不阻塞
+//	@param t
+func TestFlushAfterDestroy(t *testing.T) {
+	logger, buf := newBufferLogger(go_log.LoglevelInfo)
+	logger.Info("before destroy")
+	logger.Destroy()
+	logger.Flush()
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "before destroy") {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
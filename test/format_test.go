@@ -0,0 +1,178 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// TestJsonFormatter
+//
+//	@Description: 通过配置使用json格式输出
+//	@param t
+func TestJsonFormatter(t *testing.T) {
+	buf := &syncBuffer{}
+	logger := go_log.NewGoLog(&go_log.GoLogConfig{
+		LogLevel:       go_log.LoglevelInfo,
+		ShortLogEnable: true,
+		MsgChan:        make(chan string, 256),
+		LogFormat:      go_log.LogFormatJson,
+		JsonFormat: &go_log.JsonFormatConfig{
+			TimeKey:       "time",
+			MsgKey:        "message",
+			TimeEncoding:  go_log.TimeEncodingEpochMillis,
+			FlattenFields: true,
+		},
+	})
+	logger.SetLohWriter(buf)
+	logger.Infow("line1\nline2\t\"quoted\"\x01\u2028", "user_id", 42, "err", errors.New("boom"), "cost", 1.5)
+	logger.Destroy()
+
+	line := buf.String()
+	if strings.Count(line, "\n") != 1 || !strings.HasSuffix(line, "}\n") {
+		t.Fatalf("json output should be one line: %q", line)
+	}
+	if !strings.Contains(line, `"message":"line1\nline2\t\"quoted\"\u0001\u2028"`) {
+		t.Fatalf("control characters should be escaped: %q", line)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatalf("unmarshal %q failed,err:%v", line, err)
+	}
+	ts, ok := entry["time"].(float64)
+	if !ok || time.Since(time.UnixMilli(int64(ts))) > time.Minute {
+		t.Fatalf("unexpected time: %v", entry["time"])
+	}
+	if entry["level"] != "INFO" || entry["user_id"] != float64(42) || entry["err"] != "boom" || entry["cost"] != 1.5 {
+		t.Fatalf("unexpected entry: %v", entry)
+	}
+	if caller, _ := entry["caller"].(string); !strings.HasPrefix(caller, "format_test.go:") {
+		t.Fatalf("unexpected caller: %v", entry["caller"])
+	}
+}
+
+// TestJsonFormatterNested
+//
+//	@Description: 字段不平铺、自定义时间格式
+//	@param t
+func TestJsonFormatterNested(t *testing.T) {
+	format := go_log.NewJsonFormatter(&go_log.JsonFormatConfig{
+		CallerKey:  "-",
+		TimeLayout: go_log.DateLayout,
+	})
+	line := format(&go_log.LogEntity{
+		LogTime:  time.Date(2023, 2, 28, 14, 55, 0, 0, time.Local),
+		LogLevel: go_log.LoglevelWarn,
+		LogFile:  "demo.go",
+		LineNum:  1,
+		Msg:      "hello",
+		Fields:   go_log.Fields{{Key: "a", Value: []int{1, 2}}, {Key: "b", Value: nil}},
+	})
+	want := `{"ts":"2023-02-28","level":"WARN","msg":"hello","fields":{"a":[1,2],"b":null}}` + "\n"
+	if line != want {
+		t.Fatalf("got %q, want %q", line, want)
+	}
+}
+
+// TestLogfmtFormatter
+//
+//	@Description: logfmt格式输出
+//	@param t
+func TestLogfmtFormatter(t *testing.T) {
+	buf := &syncBuffer{}
+	logger := go_log.NewGoLog(&go_log.GoLogConfig{
+		LogLevel:       go_log.LoglevelInfo,
+		ShortLogEnable: true,
+		MsgChan:        make(chan string, 256),
+		LogFormat:      go_log.LogFormatLogfmt,
+	})
+	logger.SetLohWriter(buf)
+	logger.Infow("hello world", "user id", 42, "expr", "a=b", "quote", `say "hi"`, "empty", "", "plain", "ok")
+	logger.Destroy()
+
+	line := buf.String()
+	if !strings.HasPrefix(line, "time=") || !strings.Contains(line, " level=INFO caller=format_test.go:") {
+		t.Fatalf("unexpected output: %q", line)
+	}
+	want := ` msg="hello world" user_id=42 expr="a=b" quote="say \"hi\"" empty="" plain=ok` + "\n"
+	if !strings.HasSuffix(line, want) {
+		t.Fatalf("got %q, want suffix %q", line, want)
+	}
+
+	format := go_log.NewLogfmtFormatter(&go_log.LogfmtFormatConfig{TimeLayout: go_log.DefaultLayout, CallerKey: "-"})
+	line = format(&go_log.LogEntity{
+		LogTime:  time.Date(2023, 2, 28, 14, 55, 0, 0, time.Local),
+		LogLevel: go_log.LoglevelError,
+		Msg:      "line1\nline2",
+	})
+	want = `time="2023-02-28 14:55:00.000" level=ERROR msg="line1\nline2"` + "\n"
+	if line != want {
+		t.Fatalf("got %q, want %q", line, want)
+	}
+}
+
+// TestPatternLayout
+//
+//	@Description: 使用转换模式输出
+//	@param t
+func TestPatternLayout(t *testing.T) {
+	buf := &syncBuffer{}
+	logger := go_log.NewGoLog(&go_log.GoLogConfig{
+		LogLevel:       go_log.LoglevelInfo,
+		ShortLogEnable: false,
+		MsgChan:        make(chan string, 256),
+		Pattern:        "%d{DateLayout} [%-5p] %c{short}:%L %F - %m %X{user}%n",
+	})
+	logger.SetLohWriter(buf)
+	logger.Infow("hello", "user", "tom")
+	logger.Destroy()
+
+	line := buf.String()
+	want := time.Now().Format(string(go_log.DateLayout)) + " [INFO ] format_test.go:"
+	if !strings.HasPrefix(line, want) || !strings.HasSuffix(line, " test.TestPatternLayout - hello tom\n") {
+		t.Fatalf("unexpected output: %q", line)
+	}
+}
+
+// TestPatternModifiers
+//
+//	@Description: 宽度、截断与颜色修饰
+//	@param t
+func TestPatternModifiers(t *testing.T) {
+	entry := &go_log.LogEntity{
+		LogTime:  time.Date(2023, 2, 28, 14, 55, 0, 0, time.Local),
+		LogLevel: go_log.LoglevelWarn,
+		LogFile:  "/root/module/rotation/file.go",
+		LineNum:  7,
+		Msg:      "50%",
+	}
+	cases := []struct {
+		pattern string
+		color   bool
+		want    string
+	}{
+		{"%d{DateTimeLayout4}|%5p|%-6L|%.7c|%m%%", false, "202302281455| WARN|7     |file.go|50%%"},
+		{"%highlight{[%p]} %cyan{%m}", true, string(go_log.Yellow) + "[WARN]" + go_log.Reset + " " + string(go_log.Cyan) + "50%" + go_log.Reset},
+		{"%highlight{[%p]} %cyan{%m}", false, "[WARN] 50%"},
+		{"%-8highlight{%p}|", true, string(go_log.Yellow) + "WARN    " + go_log.Reset + "|"},
+		{"%mabc %X", false, "50%abc "},
+	}
+	for _, c := range cases {
+		layout, err := go_log.CompilePattern(c.pattern)
+		if err != nil {
+			t.Fatalf("compile %q failed,err:%v", c.pattern, err)
+		}
+		if got := layout.Format(entry, c.color); got != c.want {
+			t.Fatalf("pattern %q: got %q, want %q", c.pattern, got, c.want)
+		}
+	}
+	for _, pattern := range []string{"%", "%q", "%d{DateLayout", "%highlight", "%c{middle}", "%.0m"} {
+		if _, err := go_log.CompilePattern(pattern); err == nil {
+			t.Fatalf("pattern %q should be invalid", pattern)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// TestPanic
+//
+//	@Description: Panic级别日志在panic之前已经写出
+//	@param t
+func TestPanic(t *testing.T) {
+	logger, buf := newBufferLogger(go_log.LoglevelInfo)
+	defer logger.Destroy()
+	defer func() {
+		r := recover()
+		if r != "boom 1" {
+			t.Fatalf("unexpected panic: %v", r)
+		}
+		if !strings.Contains(buf.String(), "[PANIC]") || !strings.Contains(buf.String(), "boom 1\n") {
+			t.Fatalf("panic log should be flushed before panic: %q", buf.String())
+		}
+	}()
+	logger.Panic("boom %d", 1)
+}
+
+// TestFatal
+//
+//	@Description: Fatal级别日志落盘、执行退出钩子后以状态码1退出，在子进程中执行
+//	@param t
+func TestFatal(t *testing.T) {
+	if path := os.Getenv("GO_LOG_FATAL_FILE"); path != "" {
+		logger := go_log.NewGoLog(&go_log.GoLogConfig{
+			LogLevel:       go_log.LoglevelInfo,
+			ShortLogEnable: true,
+			MsgChan:        make(chan string, 256),
+			LogDir:         filepath.Dir(path),
+			LogName:        filepath.Base(path),
+		})
+		go_log.RegisterExitHook(func() {
+			_ = os.WriteFile(path+".hook", []byte("done"), 0644)
+		})
+		for i := 0; i < 100; i++ {
+			logger.Info("buffered %d", i)
+		}
+		logger.With("request_id", "r1").Fatal("fatal error")
+		return
+	}
+
+	path := filepath.Join(t.TempDir(), "fatal.log")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatal$")
+	cmd.Env = append(os.Environ(), "GO_LOG_FATAL_FILE="+path)
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		t.Fatalf("process should exit with code 1, err:%v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log failed,err:%v", err)
+	}
+	if !strings.Contains(string(data), "buffered 99\n") || !strings.Contains(string(data), "fatal error request_id=r1\n") {
+		t.Fatalf("buffered logs should be flushed before exit: %q", data)
+	}
+	if hook, _ := os.ReadFile(path + ".hook"); string(hook) != "done" {
+		t.Fatalf("exit hook should run before exit")
+	}
+}
+
+// TestCustomLevel
+//
+//	@Description: 注册自定义级别，参与过滤、着色与解析
+//	@param t
+func TestCustomLevel(t *testing.T) {
+	audit, err := go_log.RegisterLevel("audit", 8, go_log.Cyan)
+	if err != nil {
+		t.Fatalf("register level failed,err:%v", err)
+	}
+	notice, _ := go_log.RegisterLevel("NOTICE", go_log.LoglevelInfo.LevelNum(), go_log.BlueBold)
+	if _, err := go_log.RegisterLevel("info", 1, ""); err == nil {
+		t.Fatalf("builtin level should not be overridden")
+	}
+	if audit != "AUDIT" || audit.LevelNum() != 8 || audit.Color() != go_log.Cyan {
+		t.Fatalf("unexpected level: %s %d", audit, audit.LevelNum())
+	}
+	if level, err := go_log.ParseLevel(" Notice "); err != nil || level != notice {
+		t.Fatalf("parse level failed: %s %v", level, err)
+	}
+	if _, err := go_log.ParseLevel("verbose"); err == nil {
+		t.Fatalf("unknown level should fail to parse")
+	}
+	levels := go_log.Levels()
+	if levels[0] != go_log.LoglevelTrace || levels[len(levels)-1] != audit {
+		t.Fatalf("levels should be sorted by severity: %v", levels)
+	}
+
+	logger, buf := newBufferLogger(go_log.LoglevelFatal)
+	logger.Log(audit, "user %s login", "tom")
+	logger.Log(notice, "filtered")
+	logger.Log("UNKNOWN", "filtered")
+	logger.SetLogLevel(notice)
+	logger.Log(notice, "notice %d", 1)
+	logger.Debug("filtered")
+	logger.Destroy()
+
+	out := buf.String()
+	if !strings.Contains(out, "[AUDIT]") || !strings.Contains(out, "user tom login\n") || !strings.Contains(out, "notice 1\n") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+	if strings.Contains(out, "filtered") {
+		t.Fatalf("logs below level should be filtered: %q", out)
+	}
+}
+
+// TestVModule
+//
+//	@Description: 按文件、包路径覆盖日志级别
+//	@param t
+func TestVModule(t *testing.T) {
+	buf := &syncBuffer{}
+	g := go_log.NewGoLog(&go_log.GoLogConfig{
+		LogLevel:       go_log.LoglevelInfo,
+		ShortLogEnable: true,
+		MsgChan:        make(chan string, 256),
+		VModule:        "other*=ERROR,level_t*=DEBUG",
+	}).(*go_log.GoLog)
+	g.SetLohWriter(buf)
+	for i := 0; i < 2; i++ { //第二次命中缓存
+		g.Debug("debug %d", i)
+		g.Trace("trace %d", i)
+	}
+	if err := g.SetVModule("go-log/test/*=WARN"); err != nil {
+		t.Fatalf("set vmodule failed,err:%v", err)
+	}
+	g.Info("info filtered by package")
+	g.Warn("warn by package")
+	if err := g.SetVModule("level_test=x"); err == nil {
+		t.Fatalf("invalid vmodule should fail")
+	}
+	_ = g.SetVModule("")
+	g.Info("info by global level")
+	g.Destroy()
+
+	out := buf.String()
+	for _, want := range []string{"debug 0\n", "debug 1\n", "warn by package\n", "info by global level\n"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in output: %q", want, out)
+		}
+	}
+	if strings.Contains(out, "trace") || strings.Contains(out, "filtered") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+// TestUnregisteredLevel
+//
+//	@Description: 未注册的级别不输出，即使未设置日志级别
+//	@param t
+func TestUnregisteredLevel(t *testing.T) {
+	logger, buf := newBufferLogger("")
+	logger.Log("UNKNOWN", "filtered %d", 1)
+	logger.Log(go_log.LoglevelInfo, "kept")
+	logger.Destroy()
+	if out := buf.String(); strings.Contains(out, "filtered") || !strings.Contains(out, "kept") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
@@ -0,0 +1,96 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// TestCloseConcurrent
+//
+//	@Description: 并发写日志的同时关闭，不会panic，关闭后的日志写到标准错误，日志既不丢失也不重复
+//	@param t
+func TestCloseConcurrent(t *testing.T) {
+	stderr, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	origin := os.Stderr
+	os.Stderr = stderr
+	defer func() {
+		os.Stderr = origin
+	}()
+
+	logger, buf := newBufferLogger(go_log.LoglevelInfo)
+	const goroutines, count = 8, 500
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			child := logger.With("goroutine", "g")
+			for j := 0; j < count; j++ {
+				child.Info("concurrent")
+				if j%100 == 0 {
+					child.Flush()
+				}
+			}
+		}()
+	}
+	time.Sleep(time.Millisecond)
+	var closers sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		closers.Add(1)
+		go func() {
+			defer closers.Done()
+			if err := logger.Close(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	closers.Wait()
+
+	data, err := os.ReadFile(stderr.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	written, late := strings.Count(buf.String(), "concurrent"), strings.Count(string(data), "concurrent")
+	if written+late != goroutines*count {
+		t.Fatalf("written %d + late %d != %d", written, late, goroutines*count)
+	}
+}
+
+// TestCloseTimeout
+//
+//	@Description: 输出端阻塞时Close按ctx超时返回，关闭在后台继续完成，重复调用返回相同的结果
+//	@param t
+func TestCloseTimeout(t *testing.T) {
+	logger, w := newGateLogger(t, go_log.OverflowBlock)
+	logger.Info("blocked")
+
+	if err := logger.With("k", "v").Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := logger.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded, got %v", err)
+	}
+	close(w.gate)
+	for i := 0; i < 2; i++ {
+		if err := logger.Close(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logger.Destroy()
+	if !strings.Contains(w.String(), "blocked") {
+		t.Fatalf("unexpected output: %q", w.String())
+	}
+}
@@ -0,0 +1,266 @@
+package test
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// newNetLogger
+//
+//	@Description: 创建只输出到网络输出端的日志，只输出日志内容
+//	@param t
+//	@param config
+//	@return *go_log.GoLog
+//	@return *go_log.NetSink
+func newNetLogger(t *testing.T, config go_log.NetConfig) (*go_log.GoLog, *go_log.NetSink) {
+	config.Formatter = func(entry *go_log.LogEntity) string {
+		return entry.Msg + "\n"
+	}
+	config.MinBackoff = 10 * time.Millisecond
+	config.MaxBackoff = 50 * time.Millisecond
+	sink, err := go_log.NewNetSink(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := (&go_log.GoLogConfig{LogLevel: go_log.LoglevelInfo, Sinks: []go_log.Sink{sink}}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger, sink
+}
+
+// expectLines
+//
+//	@Description: 按顺序读取并校验日志
+//	@param t
+//	@param conn
+//	@param lines
+func expectLines(t *testing.T, conn net.Conn, lines ...string) {
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+	for _, want := range lines {
+		got, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read %q failed,err:%v", want, err)
+		}
+		if got != want+"\n" {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+	}
+}
+
+// TestNetSinkReconnect
+//
+//	@Description: 服务端断开期间日志缓存在重发缓冲区中，缓冲区写满时丢弃最早的日志，重连后按顺序补发
+//	@param t
+func TestNetSinkReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	logger, sink := newNetLogger(t, go_log.NetConfig{Network: "tcp", Address: addr, RetryBufferSize: 3})
+	defer logger.Destroy()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("first")
+	expectLines(t, conn, "first")
+	if health := sink.Health(); !health.Connected || health.Buffered != 0 {
+		t.Fatalf("unexpected health: %+v", health)
+	}
+
+	_ = conn.Close()
+	_ = ln.Close()
+	// 对端关闭后第一次写入可能仍然成功，一直写到感知断开为止
+	deadline := time.Now().Add(5 * time.Second)
+	for sink.Health().Connected {
+		if time.Now().After(deadline) {
+			t.Fatal("disconnect not detected")
+		}
+		logger.Info("probe")
+		time.Sleep(10 * time.Millisecond)
+	}
+	for i := 0; i < 5; i++ {
+		logger.Info("buffered " + strconv.Itoa(i))
+	}
+	time.Sleep(50 * time.Millisecond)
+	health := sink.Health()
+	if health.Connected || health.Buffered != 3 || health.Dropped == 0 || health.LastError == nil {
+		t.Fatalf("unexpected health: %+v", health)
+	}
+
+	if ln, err = net.Listen("tcp", addr); err != nil {
+		t.Skip("listen on the same address failed:", err)
+	}
+	defer ln.Close()
+	if conn, err = ln.Accept(); err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	expectLines(t, conn, "buffered 2", "buffered 3", "buffered 4")
+	if health = sink.Health(); !health.Connected || health.Reconnects == 0 {
+		t.Fatalf("unexpected health: %+v", health)
+	}
+}
+
+// TestNetSinkTLS
+//
+//	@Description: TLS连接
+//	@param t
+func TestNetSinkTLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(nil)
+	server.StartTLS()
+	cert, certPool := server.TLS.Certificates[0], x509.NewCertPool()
+	certPool.AddCert(server.Certificate())
+	server.Close()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	// 服务端读取时才会完成握手，因此需要在后台读取
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		received <- line
+	}()
+	logger, _ := newNetLogger(t, go_log.NetConfig{
+		Network:   "tcp",
+		Address:   ln.Addr().String(),
+		TLSConfig: &tls.Config{RootCAs: certPool, ServerName: "example.com"},
+	})
+	logger.Info("secure")
+	logger.Destroy()
+	if line := <-received; line != "secure\n" {
+		t.Fatalf("unexpected message: %q", line)
+	}
+}
+
+// TestNetSinkPartialWrite
+//
+//	@Description: 服务端读取缓慢导致写超时后，从未发送的部分继续发送，日志不重复、不截断
+//	@param t
+func TestNetSinkPartialWrite(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	logger, sink := newNetLogger(t, go_log.NetConfig{Network: "tcp", Address: ln.Addr().String(), WriteTimeout: 20 * time.Millisecond})
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	line := strings.Repeat("x", 1<<20)
+	for i := 0; i < 16; i++ {
+		logger.Info(strconv.Itoa(i) + line)
+	}
+	// 服务端暂不读取，发送方的缓冲区写满后写超时
+	logger.Flush()
+	if health := sink.Health(); !health.Connected || health.LastError == nil {
+		t.Fatalf("want write timeout, got %+v", health)
+	}
+	received := make(chan error, 1)
+	go func() {
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		r := bufio.NewReader(conn)
+		for i := 0; i < 16; i++ {
+			got, err := r.ReadString('\n')
+			if err != nil {
+				received <- err
+				return
+			}
+			if got != strconv.Itoa(i)+line+"\n" {
+				received <- errors.New("unexpected line " + strconv.Itoa(i) + ": " + strconv.Quote(got[:16]) + " of " + strconv.Itoa(len(got)) + " bytes")
+				return
+			}
+		}
+		received <- nil
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for sink.Health().Buffered > 0 && time.Now().Before(deadline) {
+		_ = logger.Sync()
+	}
+	if err := <-received; err != nil {
+		t.Fatal(err)
+	}
+	logger.Destroy()
+}
+
+// TestNetSinkPartialWriteOverflow
+//
+//	@Description: 写超时后重发缓冲区写满时保留已部分发送的日志，服务端收到的每条日志都是完整的
+//	@param t
+func TestNetSinkPartialWriteOverflow(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	logger, sink := newNetLogger(t, go_log.NetConfig{Network: "tcp", Address: ln.Addr().String(), WriteTimeout: 20 * time.Millisecond, RetryBufferSize: 2})
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	line := strings.Repeat("x", 1<<20)
+	for i := 0; i < 16; i++ {
+		logger.Info(strconv.Itoa(i) + line)
+	}
+	logger.Flush()
+	if health := sink.Health(); health.Dropped == 0 {
+		t.Fatalf("want dropped logs, got %+v", health)
+	}
+	received := make(chan error, 1)
+	go func() {
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		r := bufio.NewReader(conn)
+		last := -1
+		for {
+			got, err := r.ReadString('\n')
+			if err != nil {
+				if got != "" {
+					err = errors.New("truncated line of " + strconv.Itoa(len(got)) + " bytes")
+				} else if last >= 0 {
+					err = nil
+				}
+				received <- err
+				return
+			}
+			i, convErr := strconv.Atoi(strings.TrimSuffix(got, line+"\n"))
+			if convErr != nil || i <= last || got != strconv.Itoa(i)+line+"\n" {
+				received <- errors.New("unexpected line " + strconv.Quote(got[:16]) + " of " + strconv.Itoa(len(got)) + " bytes after " + strconv.Itoa(last))
+				return
+			}
+			last = i
+		}
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for sink.Health().Buffered > 0 && time.Now().Before(deadline) {
+		_ = logger.Sync()
+	}
+	logger.Destroy()
+	if err := <-received; err != nil {
+		t.Fatal(err)
+	}
+}
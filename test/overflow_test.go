@@ -0,0 +1,102 @@
+package test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// gateWriter
+// @Description: 在gate关闭前阻塞写入的输出流，用于模拟缓慢的输出端
+type gateWriter struct {
+	syncBuffer
+	gate chan struct{}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	return w.syncBuffer.Write(p)
+}
+
+// newGateLogger
+//
+//	@Description: 创建缓冲区长度为1、输出被阻塞的日志
+//	@param policy
+//	@return *go_log.GoLog
+//	@return *gateWriter
+func newGateLogger(t *testing.T, policy go_log.OverflowPolicy) (*go_log.GoLog, *gateWriter) {
+	w := &gateWriter{gate: make(chan struct{})}
+	logger, err := (&go_log.GoLogConfig{
+		LogLevel:       go_log.LoglevelInfo,
+		ShortLogEnable: true,
+		BufferSize:     1,
+		Writer:         w,
+		OverflowPolicy: policy,
+		BlockTimeout:   "10ms",
+	}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger, w
+}
+
+// TestOverflowDrop
+//
+//	@Description: 管道写满时丢弃最新或最早的日志，排空后输出丢弃数量
+//	@param t
+func TestOverflowDrop(t *testing.T) {
+	for _, policy := range []go_log.OverflowPolicy{go_log.OverflowDropNewest, go_log.OverflowDropOldest, go_log.OverflowBlockTimeout} {
+		t.Run(string(policy), func(t *testing.T) {
+			logger, w := newGateLogger(t, policy)
+			for i := 0; i < 10; i++ {
+				logger.Info("msg %d", i)
+			}
+			close(w.gate)
+			logger.Destroy()
+
+			dropped := logger.Dropped()
+			if dropped < 7 {
+				t.Fatalf("at least 7 messages should be dropped, got %d", dropped)
+			}
+			out := w.String()
+			if got := strings.Count(out, "msg "); uint64(got)+dropped != 10 {
+				t.Fatalf("written %d + dropped %d != 10: %q", got, dropped, out)
+			}
+			if !strings.Contains(out, strconv.FormatUint(dropped, 10)+" messages dropped") {
+				t.Fatalf("dropped report missing: %q", out)
+			}
+			if policy == go_log.OverflowDropOldest && !strings.Contains(out, "msg 9\n") {
+				t.Fatalf("newest message should be kept: %q", out)
+			}
+		})
+	}
+}
+
+// TestOverflowSyncWrite
+//
+//	@Description: 管道写满时在调用方协程中写出，不丢弃日志
+//	@param t
+func TestOverflowSyncWrite(t *testing.T) {
+	logger, w := newGateLogger(t, go_log.OverflowSyncWrite)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(w.gate)
+	}()
+	for i := 0; i < 10; i++ {
+		logger.Info("msg %d", i)
+	}
+	logger.Destroy()
+
+	if logger.Dropped() != 0 {
+		t.Fatalf("no message should be dropped, got %d", logger.Dropped())
+	}
+	out := w.String()
+	for i := 0; i < 10; i++ {
+		if !strings.Contains(out, "msg "+strconv.Itoa(i)+"\n") {
+			t.Fatalf("msg %d missing: %q", i, out)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package test
+
+import (
+	"sync"
+	"testing"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// TestConcurrentReconfigure
+//
+//	@Description: 日志输出的同时在多个协程中修改配置，需配合-race运行
+//	@param t
+func TestConcurrentReconfigure(t *testing.T) {
+	logger, err := (&go_log.GoLogConfig{
+		LogLevel:       go_log.LoglevelInfo,
+		ShortLogEnable: true,
+		BufferSize:     64,
+		Writer:         discardWriter{},
+		WriterLogLevel: go_log.LoglevelInfo,
+	}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Destroy()
+	child := logger.With("k", "v")
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				logger.Info("info %d", 1)
+				child.Debug("debug %d", 2)
+				logger.Warn("warn")
+			}
+		}()
+	}
+
+	setters := []func(i int){
+		func(i int) {
+			if i%2 == 0 {
+				logger.SetLogLevel(go_log.LoglevelDebug)
+			} else {
+				logger.SetLogLevel(go_log.LoglevelInfo)
+			}
+		},
+		func(i int) { logger.ShortLogEnable(i%2 == 0) },
+		func(i int) { logger.ConsoleEnable(false) },
+		func(i int) { logger.ColorEnable(i%2 == 0) },
+		func(i int) {
+			if i%2 == 0 {
+				_ = logger.SetLogPattern("%d %p %m%n")
+			} else {
+				_ = logger.SetLogPattern("")
+			}
+		},
+		func(i int) {
+			if i%2 == 0 {
+				_ = logger.SetVModule("race_test=debug")
+			} else {
+				_ = logger.SetVModule("")
+			}
+		},
+		func(i int) {
+			if i%2 == 0 {
+				logger.SetLogFormatter(func(entry *go_log.LogEntity) string { return entry.Msg + "\n" })
+			} else {
+				logger.SetLogFormatter(nil)
+			}
+		},
+		func(i int) { logger.SetLohWriter(discardWriter{}) },
+		func(i int) {
+			_ = logger.ApplyConfig(&go_log.GoLogConfig{
+				LogLevel:        go_log.LoglevelInfo,
+				ShortLogEnable:  i%2 == 0,
+				ConsoleLogLevel: go_log.LoglevelInfo,
+				OverflowPolicy:  go_log.OverflowDropNewest,
+			})
+		},
+	}
+	var setterWg sync.WaitGroup
+	for _, setter := range setters {
+		setterWg.Add(1)
+		go func(setter func(i int)) {
+			defer setterWg.Done()
+			for i := 0; i < 200; i++ {
+				setter(i)
+			}
+		}(setter)
+	}
+	setterWg.Wait()
+	close(stop)
+	wg.Wait()
+	logger.Flush()
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// rotateExternally
+//
+//	@Description: 模拟logrotate的create模式：移动日志文件后创建同名的空文件
+//	@param t
+//	@param path
+func rotateExternally(t *testing.T, path string) {
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// expectFile
+//
+//	@Description: 校验文件包含want且不包含notWant
+//	@param t
+//	@param path
+//	@param want
+//	@param notWant
+func expectFile(t *testing.T, path, want, notWant string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), want) || strings.Contains(string(data), notWant) {
+		t.Fatalf("%s: want %q without %q, got %q", filepath.Base(path), want, notWant, data)
+	}
+}
+
+// TestReopen
+//
+//	@Description: 日志文件被外部移动后调用Reopen写入新文件
+//	@param t
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	logger := go_log.NewGoLog(&go_log.GoLogConfig{LogLevel: go_log.LoglevelInfo, LogDir: dir, LogName: "app.log"})
+	defer logger.Destroy()
+	logger.Info("before")
+	logger.Flush()
+	rotateExternally(t, path)
+	if err := logger.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("after")
+	logger.Flush()
+	expectFile(t, path+".1", "before", "after")
+	expectFile(t, path, "after", "before")
+}
+
+// TestReopenOnInodeChange
+//
+//	@Description: 不调用Reopen时，最多约一秒后发现文件被替换并重新打开
+//	@param t
+func TestReopenOnInodeChange(t *testing.T) {
+	for _, template := range []string{"", "{dir}/{name}-{index}{ext}"} {
+		dir := t.TempDir()
+		logger := go_log.NewGoLog(&go_log.GoLogConfig{
+			LogLevel:         go_log.LoglevelInfo,
+			LogDir:           dir,
+			LogName:          "app.log",
+			RollNameTemplate: template,
+		})
+		logger.Info("before")
+		logger.Flush()
+		path := filepath.Join(dir, "app.log")
+		if template != "" {
+			path = filepath.Join(dir, "app-1.log")
+		}
+		rotateExternally(t, path)
+		time.Sleep(1100 * time.Millisecond)
+		logger.Info("after")
+		logger.Destroy()
+		expectFile(t, path+".1", "before", "after")
+		expectFile(t, path, "after", "before")
+	}
+}
@@ -0,0 +1,194 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// TestRetention
+//
+//	@Description: 每次滚动后只保留MaxBackups个压缩文件，删除时回调OnDelete，不删除其他日志的文件
+//	@param t
+func TestRetention(t *testing.T) {
+	dir := t.TempDir()
+	others := []string{"other.log-1.zip", "app.log-old.zip", "app.log.zip", "app.log-worker-1.zip"}
+	for _, name := range others {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var mu sync.Mutex
+	var deleted []string
+	logger := go_log.NewGoLog(&go_log.GoLogConfig{
+		LogLevel:      go_log.LoglevelInfo,
+		LogDir:        dir,
+		LogName:       "app.log",
+		RollLogBySize: 1,
+		MaxBackups:    2,
+		OnDelete: func(path string) {
+			mu.Lock()
+			defer mu.Unlock()
+			deleted = append(deleted, path)
+		},
+	})
+	line := strings.Repeat("x", 200)
+	for i := 0; i < 100; i++ {
+		logger.Info(line)
+	}
+	logger.Destroy()
+
+	var backups []string
+	for _, name := range others {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("%s should be kept: %v", name, err)
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "app.log-") && strings.HasSuffix(entry.Name(), ".zip") && entry.Name() != "app.log-old.zip" && entry.Name() != "app.log-worker-1.zip" {
+			backups = append(backups, entry.Name())
+		}
+	}
+	if len(backups) != 2 {
+		t.Fatalf("want 2 backups, got %v", backups)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(deleted) == 0 {
+		t.Fatal("OnDelete not called")
+	}
+	for _, path := range deleted {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("%s should be deleted", path)
+		}
+		for _, backup := range backups {
+			if filepath.Base(path) == backup {
+				t.Fatalf("%s is reported deleted but kept", backup)
+			}
+		}
+	}
+	// 保留的是最新的两个压缩文件
+	sort.Strings(backups)
+	for _, path := range deleted {
+		if name := filepath.Base(path); len(name) > len(backups[0]) || (len(name) == len(backups[0]) && name > backups[0]) {
+			t.Fatalf("newer backup %s deleted, kept %v", name, backups)
+		}
+	}
+}
+
+// TestRetentionConfig
+//
+//	@Description: 不合法的保留策略
+//	@param t
+func TestRetentionConfig(t *testing.T) {
+	for _, config := range []go_log.GoLogConfig{
+		{MaxBackups: -1},
+		{MaxAge: "7d"},
+		{MaxAge: "-1h"},
+		{MaxTotalSize: -1},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Fatalf("want error for %+v", config)
+		}
+	}
+	config := go_log.GoLogConfig{MaxBackups: 3, MaxAge: "168h", MaxTotalSize: 1024}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestRollByTimeAndSize
+//
+//	@Description: 同时按时间与大小滚动，滚动文件名为 日志文件名-时间块.序号，每个时间块的序号从1开始连续递增
+//	@param t
+func TestRollByTimeAndSize(t *testing.T) {
+	dir := t.TempDir()
+	logger := go_log.NewGoLog(&go_log.GoLogConfig{
+		LogLevel:      go_log.LoglevelInfo,
+		LogDir:        dir,
+		LogName:       "app.log",
+		RollLogByTime: "1s",
+		RollLogBySize: 1,
+	})
+	line := strings.Repeat("x", 200)
+	for i := 0; i < 50; i++ {
+		logger.Info(line)
+	}
+	logger.Destroy()
+
+	pattern := regexp.MustCompile(`^app\.log-(\d{12})\.(\d+)\.zip$`)
+	indexes := map[string][]int{}
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if entry.Name() == "app.log" {
+			continue
+		}
+		match := pattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			t.Fatalf("unexpected file %s", entry.Name())
+		}
+		index, _ := strconv.Atoi(match[2])
+		indexes[match[1]] = append(indexes[match[1]], index)
+	}
+	var total int
+	for block, list := range indexes {
+		sort.Ints(list)
+		for i, index := range list {
+			if index != i+1 {
+				t.Fatalf("indexes of block %s are not continuous: %v", block, list)
+			}
+		}
+		total += len(list)
+	}
+	// 50行约10KB，时间块内按1KB拆分
+	if total < 5 {
+		t.Fatalf("want at least 5 archives, got %v", indexes)
+	}
+}
+
+// TestRetentionOnDeleteLogs
+//
+//	@Description: OnDelete回调通过同一个日志输出，管道写满时也不会死锁
+//	@param t
+func TestRetentionOnDeleteLogs(t *testing.T) {
+	var logger go_log.ILogger
+	ready := make(chan struct{})
+	logger = go_log.NewGoLog(&go_log.GoLogConfig{
+		LogLevel:      go_log.LoglevelInfo,
+		LogDir:        t.TempDir(),
+		LogName:       "app.log",
+		BufferSize:    1,
+		RollLogBySize: 1,
+		MaxBackups:    1,
+		OnDelete: func(path string) {
+			<-ready
+			for i := 0; i < 10; i++ {
+				logger.Info("deleted %s", filepath.Base(path))
+			}
+		},
+	})
+	close(ready)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		line := strings.Repeat("x", 200)
+		for i := 0; i < 200; i++ {
+			logger.Info(line)
+		}
+		logger.Destroy()
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("deadlock when OnDelete logs")
+	}
+}
@@ -0,0 +1,195 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// TestNextRollTime
+//
+//	@Description: 滚动时间按所在时区的日历对齐
+//	@param t
+func TestNextRollTime(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip("load location failed:", err)
+	}
+	// 2023-02-28是周二
+	now := time.Date(2023, 2, 28, 14, 56, 23, 0, shanghai)
+	for _, c := range []struct {
+		spec string
+		want time.Time
+	}{
+		{"5m", time.Date(2023, 2, 28, 15, 0, 0, 0, shanghai)},
+		{"24h", time.Date(2023, 3, 1, 0, 0, 0, 0, shanghai)},
+		{"hourly", time.Date(2023, 2, 28, 15, 0, 0, 0, shanghai)},
+		{"daily", time.Date(2023, 3, 1, 0, 0, 0, 0, shanghai)},
+		{"@midnight", time.Date(2023, 3, 1, 0, 0, 0, 0, shanghai)},
+		{"weekly", time.Date(2023, 3, 6, 0, 0, 0, 0, shanghai)},
+		{"monthly", time.Date(2023, 3, 1, 0, 0, 0, 0, shanghai)},
+		{"0 3 * * *", time.Date(2023, 3, 1, 3, 0, 0, 0, shanghai)},
+		{"*/15 * * * *", time.Date(2023, 2, 28, 15, 0, 0, 0, shanghai)},
+		{"30 2 * * 6,7", time.Date(2023, 3, 4, 2, 30, 0, 0, shanghai)},
+		{"0 0 1,15 * *", time.Date(2023, 3, 1, 0, 0, 0, 0, shanghai)},
+		{"0 12 29 2 *", time.Date(2024, 2, 29, 12, 0, 0, 0, shanghai)},
+	} {
+		got, err := go_log.NextRollTime(c.spec, now)
+		if err != nil {
+			t.Fatalf("%s: %v", c.spec, err)
+		}
+		if !got.Equal(c.want) {
+			t.Fatalf("%s: want %v, got %v", c.spec, c.want, got)
+		}
+	}
+	if got, _ := go_log.NextRollTime("monthly", time.Date(2023, 12, 31, 23, 0, 0, 0, shanghai)); !got.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, shanghai)) {
+		t.Fatalf("monthly at the end of year: %v", got)
+	}
+	// 不能整除一天的间隔在次日零点重新开始，整天数的间隔按日期对齐
+	for _, c := range []struct {
+		spec     string
+		now      time.Time
+		want     time.Time
+		location *time.Location
+	}{
+		{"7h", now, time.Date(2023, 2, 28, 21, 0, 0, 0, shanghai), shanghai},
+		{"7h", time.Date(2023, 2, 28, 22, 0, 0, 0, shanghai), time.Date(2023, 3, 1, 0, 0, 0, 0, shanghai), shanghai},
+		{"48h", now, time.Date(2023, 3, 2, 0, 0, 0, 0, shanghai), shanghai},
+	} {
+		if got, _ := go_log.NextRollTime(c.spec, c.now); !got.Equal(c.want) {
+			t.Fatalf("%s at %v: want %v, got %v", c.spec, c.now, c.want, got)
+		}
+	}
+	// 夏令时切换后仍按钟表时间对齐
+	if newYork, err := time.LoadLocation("America/New_York"); err == nil {
+		for _, c := range []struct {
+			spec string
+			now  time.Time
+			want time.Time
+		}{
+			{"6h", time.Date(2023, 3, 12, 1, 0, 0, 0, newYork), time.Date(2023, 3, 12, 6, 0, 0, 0, newYork)},
+			{"1h", time.Date(2023, 3, 12, 1, 30, 0, 0, newYork), time.Date(2023, 3, 12, 3, 0, 0, 0, newYork)},
+			{"6h", time.Date(2023, 11, 5, 1, 0, 0, 0, newYork), time.Date(2023, 11, 5, 6, 0, 0, 0, newYork)},
+			{"30m", time.Date(2023, 11, 5, 1, 0, 0, 0, newYork).Add(time.Hour + 20*time.Minute), time.Date(2023, 11, 5, 2, 0, 0, 0, newYork)},
+		} {
+			if got, _ := go_log.NextRollTime(c.spec, c.now); !got.Equal(c.want) {
+				t.Fatalf("%s at %v: want %v, got %v", c.spec, c.now, c.want, got)
+			}
+		}
+	}
+	for _, spec := range []string{"500ms", "yearly", "0 3 * *", "60 * * * *", "0 0 30 2 *", "*/0 * * * *"} {
+		if _, err := go_log.NextRollTime(spec, now); err == nil {
+			t.Fatalf("want error for %q", spec)
+		}
+	}
+	for _, config := range []go_log.GoLogConfig{
+		{RollLocation: "Mars/Olympus"},
+		{RollTimeLayout: "2006/01/02"},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Fatalf("want error for %+v", config)
+		}
+	}
+}
+
+// TestRollLocation
+//
+//	@Description: 滚动文件名中的时间块使用RollLocation时区与RollTimeLayout格式
+//	@param t
+func TestRollLocation(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip("load location failed:", err)
+	}
+	dir := t.TempDir()
+	logger := go_log.NewGoLog(&go_log.GoLogConfig{
+		LogLevel:       go_log.LoglevelInfo,
+		LogDir:         dir,
+		LogName:        "app.log",
+		RollLogByTime:  "1s",
+		RollLocation:   "Asia/Shanghai",
+		RollTimeLayout: "20060102-150405",
+	})
+	start := time.Now()
+	logger.Info("first block")
+	logger.Flush()
+	time.Sleep(1100 * time.Millisecond)
+	logger.Info("second block")
+	logger.Destroy()
+
+	pattern := regexp.MustCompile(`^app\.log-(\d{8}-\d{6})\.zip$`)
+	entries, _ := os.ReadDir(dir)
+	var archives []string
+	for _, entry := range entries {
+		if match := pattern.FindStringSubmatch(entry.Name()); match != nil {
+			block, err := time.ParseInLocation("20060102-150405", match[1], shanghai)
+			if err != nil || block.Before(start.Add(-2*time.Second)) || block.After(time.Now()) {
+				t.Fatalf("unexpected time block %s", entry.Name())
+			}
+			archives = append(archives, entry.Name())
+		}
+	}
+	if len(archives) != 1 {
+		t.Fatalf("want 1 archive, got %v", archives)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+	if !strings.Contains(string(data), "second block") || strings.Contains(string(data), "first block") {
+		t.Fatalf("unexpected log file: %q", data)
+	}
+}
+
+// TestRollBlockAlignment
+//
+//	@Description: 第一个时间块对齐到滚动计划，而不是文件的修改时间或启动时间
+//	@param t
+func TestRollBlockAlignment(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(name, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-2 * time.Minute).Truncate(time.Minute).Add(39 * time.Second)
+	if err := os.Chtimes(name, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	logger := go_log.NewGoLog(&go_log.GoLogConfig{
+		LogLevel:       go_log.LoglevelInfo,
+		LogDir:         dir,
+		LogName:        "app.log",
+		RollLogByTime:  "1m",
+		RollTimeLayout: "20060102150405",
+	})
+	logger.Info("new block")
+	logger.Destroy()
+	archive := "app.log-" + modTime.Truncate(time.Minute).Format("20060102150405") + ".zip"
+	if _, err := os.Stat(filepath.Join(dir, archive)); err != nil {
+		entries, _ := os.ReadDir(dir)
+		t.Fatalf("want %s, got %v", archive, entries)
+	}
+
+	dir = t.TempDir()
+	before := time.Now()
+	logger = go_log.NewGoLog(&go_log.GoLogConfig{
+		LogLevel:         go_log.LoglevelInfo,
+		LogDir:           dir,
+		LogName:          "app.log",
+		RollLogByTime:    go_log.RollHourly,
+		RollNameTemplate: "{name}-{time:20060102-150405}{ext}",
+	})
+	logger.Info("hourly")
+	logger.Destroy()
+	after := time.Now()
+	for _, block := range []time.Time{before, after} {
+		hour := time.Date(block.Year(), block.Month(), block.Day(), block.Hour(), 0, 0, 0, time.Local)
+		if _, err := os.Stat(filepath.Join(dir, "app-"+hour.Format("20060102-150405")+".log")); err == nil {
+			return
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	t.Fatalf("want a file named after the hour, got %v", entries)
+}
@@ -0,0 +1,44 @@
+//go:build !windows
+
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// TestReopenOnSighup
+//
+//	@Description: 收到SIGHUP后重新打开日志文件
+//	@param t
+func TestReopenOnSighup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	logger := go_log.NewGoLog(&go_log.GoLogConfig{LogLevel: go_log.LoglevelInfo, LogDir: dir, LogName: "app.log", ReopenOnSighup: true})
+	defer logger.Destroy()
+	logger.Info("before")
+	logger.Flush()
+	rotateExternally(t, path)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	// 等待信号处理完成，新文件的检查间隔为1秒，这里必须在1秒内由信号触发重新打开
+	deadline := time.Now().Add(500 * time.Millisecond)
+	for {
+		logger.Info("after")
+		logger.Flush()
+		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("log file not reopened after SIGHUP")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	expectFile(t, path, "after", "before")
+}
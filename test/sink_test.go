@@ -0,0 +1,83 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// TestSinkLevel
+//
+//	@Description: 日志文件与输出流按各自的最低级别过滤，且不输出颜色
+//	@param t
+func TestSinkLevel(t *testing.T) {
+	dir := t.TempDir()
+	buf := &syncBuffer{}
+	logger := go_log.NewGoLog(&go_log.GoLogConfig{
+		LogLevel:       go_log.LoglevelTrace,
+		ShortLogEnable: true,
+		BufferSize:     256,
+		Writer:         buf,
+		ColorEnable:    true,
+		LogDir:         dir,
+		LogName:        "sink.log",
+		FileLogLevel:   go_log.LoglevelWarn,
+	})
+	logger.Trace("trace message")
+	logger.Warn("warn message")
+	logger.Destroy()
+
+	data, err := os.ReadFile(filepath.Join(dir, "sink.log"))
+	if err != nil {
+		t.Fatalf("read log failed,err:%v", err)
+	}
+	if strings.Contains(string(data), "trace message") || !strings.Contains(string(data), "warn message") {
+		t.Fatalf("file should only contain warn logs: %q", data)
+	}
+	if !strings.Contains(buf.String(), "trace message") || !strings.Contains(buf.String(), "warn message") {
+		t.Fatalf("writer should contain all logs: %q", buf.String())
+	}
+	if strings.Contains(string(data), "\033[") || strings.Contains(buf.String(), "\033[") {
+		t.Fatalf("file and writer should not contain color codes: %q %q", data, buf.String())
+	}
+}
+
+// TestAddSink
+//
+//	@Description: 自定义输出端使用自己的格式化器与级别，未设置格式化器时使用日志的格式
+//	@param t
+func TestAddSink(t *testing.T) {
+	logger, buf := newBufferLogger(go_log.LoglevelDebug)
+	errBuf := &syncBuffer{}
+	logger.AddSink(go_log.NewWriterSink(errBuf, go_log.SinkConfig{
+		Level: go_log.LoglevelError,
+		Formatter: func(entry *go_log.LogEntity) string {
+			return string(entry.LogLevel) + ":" + entry.Msg + "\n"
+		},
+	}))
+	colorBuf := &syncBuffer{}
+	logger.AddSink(go_log.NewWriterSink(colorBuf, go_log.SinkConfig{ColorEnable: true}))
+	if err := logger.(*go_log.GoLog).SetLogPattern("%highlight{%p} %m%n"); err != nil {
+		t.Fatal(err)
+	}
+	logger.Debug("debug message")
+	logger.Error("error message")
+	logger.Info("plain message")
+	logger.Destroy()
+
+	if got := errBuf.String(); got != "ERROR:error message\n" {
+		t.Fatalf("unexpected error sink output: %q", got)
+	}
+	want := go_log.Magenta.WithColorEnd("DEBUG") + " debug message\n" +
+		go_log.Red.WithColorEnd("ERROR") + " error message\n" +
+		go_log.Green.WithColorEnd("INFO") + " plain message\n"
+	if got := colorBuf.String(); got != want {
+		t.Fatalf("unexpected color sink output: %q", got)
+	}
+	if got := buf.String(); got != "DEBUG debug message\nERROR error message\nINFO plain message\n" {
+		t.Fatalf("unexpected writer output: %q", got)
+	}
+}
@@ -0,0 +1,49 @@
+//go:build go1.21
+
+package test
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// TestSlogHandler
+//
+//	@Description: 使用slog作为前端，GoLog负责输出
+//	@param t
+func TestSlogHandler(t *testing.T) {
+	buf := &syncBuffer{}
+	g := go_log.DefaultGoLog()
+	g.ConsoleEnable(false)
+	g.ColorEnable(false)
+	g.SetLogLevel(go_log.LoglevelDebug)
+	g.SetLohWriter(buf)
+	logger := slog.New(go_log.NewSlogHandler(g)).With("app", "demo")
+	logger.Debug("debug msg", "k", 1)
+	logger.WithGroup("req").Info("grouped", "id", "r1", slog.Group("user", "name", "tom"))
+	logger.Warn("warn msg", slog.Group("", "inline", true))
+	logger.Log(context.Background(), slog.LevelDebug-4, "trace msg")
+	g.Destroy()
+
+	out := buf.String()
+	for _, want := range []string{
+		"[DEBUG]", "debug msg app=demo k=1\n",
+		"[INFO]", "grouped app=demo req.id=r1 req.user.name=tom\n",
+		"[WARN]", "warn msg app=demo inline=true\n",
+		"slog_test.go:",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in output: %q", want, out)
+		}
+	}
+	if strings.Contains(out, "trace msg") {
+		t.Fatalf("trace log should be filtered: %q", out)
+	}
+	if go_log.SlogLevel(slog.LevelDebug-4) != go_log.LoglevelTrace || go_log.SlogLevel(slog.LevelError+4) != go_log.LoglevelError {
+		t.Fatalf("unexpected level mapping")
+	}
+}
@@ -0,0 +1,208 @@
+package test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// newSyslogLogger
+//
+//	@Description: 创建只输出到syslog的日志
+//	@param t
+//	@param config
+//	@return *go_log.GoLog
+func newSyslogLogger(t *testing.T, config go_log.SyslogConfig) *go_log.GoLog {
+	config.AppName, config.Hostname, config.ProcID = "app", "host", "42"
+	sink, err := go_log.NewSyslogSink(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := (&go_log.GoLogConfig{LogLevel: go_log.LoglevelTrace, Sinks: []go_log.Sink{sink}}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+// readFrame
+//
+//	@Description: 读取一个octet-counting分帧的消息
+//	@param r
+//	@return string
+//	@return error
+func readFrame(r *bufio.Reader) (string, error) {
+	length, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(length))
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	_, err = io.ReadFull(r, buf)
+	return string(buf), err
+}
+
+// TestSyslogUDP
+//
+//	@Description: RFC 5424格式，字段输出为结构化数据
+//	@param t
+func TestSyslogUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	logger := newSyslogLogger(t, go_log.SyslogConfig{Network: "udp", Address: conn.LocalAddr().String(), Facility: go_log.FacilityLocal0})
+	logger.Warnw("disk full", "path", `/data "a"`, "used pct", 99)
+	logger.Destroy()
+
+	buf := make([]byte, 2048)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	re := regexp.MustCompile(`^<132>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}(Z|[+-]\d\d:\d\d) host app 42 - ` +
+		regexp.QuoteMeta(`[fields@32473 path="/data \"a\"" used_pct="99"] disk full`) + `$`)
+	if !re.Match(buf[:n]) {
+		t.Fatalf("unexpected message: %q", buf[:n])
+	}
+}
+
+// TestSyslogTCP
+//
+//	@Description: RFC 3164格式，TCP使用octet-counting分帧，连接断开后重连
+//	@param t
+func TestSyslogTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	logger := newSyslogLogger(t, go_log.SyslogConfig{Network: "tcp", Address: ln.Addr().String(), Format: go_log.SyslogRFC3164})
+	defer logger.Destroy()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Errorw("failed", "code", 500)
+	logger.Debug("debug %d", 1)
+	r := bufio.NewReader(conn)
+	for _, want := range []string{`^<11>\w{3} [ \d]\d \d\d:\d\d:\d\d host app\[42\]: failed code=500$`, `^<15>.* app\[42\]: debug 1$`} {
+		msg, err := readFrame(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !regexp.MustCompile(want).MatchString(msg) {
+			t.Fatalf("unexpected message: %q", msg)
+		}
+	}
+
+	// 服务端断开后继续写日志，直到在后台重新建立连接并收到日志
+	_ = conn.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err == nil {
+			accepted <- c
+		}
+	}()
+	var received chan string
+	deadline := time.After(5 * time.Second)
+	for i := 0; ; i++ {
+		logger.Info("after reconnect %d", i)
+		select {
+		case c := <-accepted:
+			defer c.Close()
+			received = make(chan string, 1)
+			go func() {
+				msg, _ := readFrame(bufio.NewReader(c))
+				received <- msg
+			}()
+		case msg := <-received:
+			if !strings.Contains(msg, "after reconnect") {
+				t.Fatalf("unexpected message after reconnect: %q", msg)
+			}
+			return
+		case <-deadline:
+			t.Fatal("syslog sink did not reconnect")
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+// TestSyslogUnix
+//
+//	@Description: unix数据报套接字
+//	@param t
+func TestSyslogUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	conn, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Skip("unixgram not supported:", err)
+	}
+	defer conn.Close()
+	logger := newSyslogLogger(t, go_log.SyslogConfig{Network: "unixgram", Address: path})
+	logger.Info("hello")
+	logger.Destroy()
+
+	buf := make([]byte, 2048)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg := string(buf[:n]); !strings.HasPrefix(msg, "<14>1 ") || !strings.HasSuffix(msg, " host app 42 - - hello") {
+		t.Fatalf("unexpected message: %q", msg)
+	}
+}
+
+// TestSyslogBackoff
+//
+//	@Description: 服务端不可用时在后台按退避间隔重连，期间的日志丢弃并计数，写日志时不建立连接
+//	@param t
+func TestSyslogBackoff(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := go_log.SyslogConfig{Network: "tcp", Address: ln.Addr().String(), MinBackoff: time.Hour, AppName: "app"}
+	sink, err := go_log.NewSyslogSink(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.Close()
+	_ = ln.Close()
+	logger, err := (&go_log.GoLogConfig{LogLevel: go_log.LoglevelInfo, Sinks: []go_log.Sink{sink}}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Destroy()
+	for i := 0; i < 100; i++ {
+		logger.Info("dropped %d", i)
+		if i == 0 {
+			// 等待服务端的关闭被感知，使后续写入失败
+			logger.Flush()
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	logger.Flush()
+	if dropped := sink.Dropped(); dropped < 95 {
+		t.Fatalf("want logs dropped while disconnected, got %d", dropped)
+	}
+}
@@ -0,0 +1,178 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	go_log "github.com/yuhao-jack/go-log"
+)
+
+// TestRollNameTemplate
+//
+//	@Description: 按模板生成日志文件名，扩展名保持在末尾，符号链接指向当前日志文件，重新创建日志后继续写入当前文件
+//	@param t
+func TestRollNameTemplate(t *testing.T) {
+	dir := t.TempDir()
+	config := &go_log.GoLogConfig{
+		LogLevel:         go_log.LoglevelInfo,
+		LogDir:           dir,
+		LogName:          "app.log",
+		RollLogByTime:    go_log.RollDaily,
+		RollLogBySize:    1,
+		RollNameTemplate: "{dir}/{name}-{time:20060102}.{index}{ext}",
+		CurrentLink:      "current",
+	}
+	logger := go_log.NewGoLog(config)
+	line := strings.Repeat("x", 200)
+	for i := 0; i < 50; i++ {
+		logger.Info(line)
+	}
+	logger.Info("last")
+	logger.Destroy()
+
+	day := time.Now().Format("20060102")
+	pattern := regexp.MustCompile(`^app-` + day + `\.(\d+)\.log(\.zip)?$`)
+	var active string
+	var archives, maxIndex int
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if entry.Name() == "current" {
+			continue
+		}
+		match := pattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			t.Fatalf("unexpected file %s", entry.Name())
+		}
+		index, _ := strconv.Atoi(match[1])
+		if index > maxIndex {
+			maxIndex = index
+		}
+		if match[2] == "" {
+			if active != "" {
+				t.Fatalf("more than one uncompressed file: %s, %s", active, entry.Name())
+			}
+			active = entry.Name()
+		} else {
+			archives++
+		}
+	}
+	if archives < 4 || active != "app-"+day+"."+strconv.Itoa(maxIndex)+".log" {
+		t.Fatalf("unexpected files: %d archives, active %q, max index %d", archives, active, maxIndex)
+	}
+	if target, err := os.Readlink(filepath.Join(dir, "current")); err != nil || target != active {
+		t.Fatalf("current links to %q, want %q, err:%v", target, active, err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "current"))
+	if !strings.Contains(string(data), "last") {
+		t.Fatalf("unexpected current file: %q", data)
+	}
+
+	logger = go_log.NewGoLog(config)
+	logger.Info("resumed")
+	logger.Destroy()
+	data, _ = os.ReadFile(filepath.Join(dir, active))
+	if !strings.Contains(string(data), "last") || !strings.Contains(string(data), "resumed") {
+		t.Fatalf("want to resume %s, got %q", active, data)
+	}
+}
+
+// TestRollNameTemplateConfig
+//
+//	@Description: 不合法的模板与符号链接
+//	@param t
+func TestRollNameTemplateConfig(t *testing.T) {
+	for _, config := range []go_log.GoLogConfig{
+		{LogName: "app.log", RollNameTemplate: "{name}{ext}"},
+		{LogName: "app.log", RollNameTemplate: "{name}-{date}{ext}"},
+		{LogName: "app.log", RollNameTemplate: "{name}-{time{ext}"},
+		{LogName: "app.log", RollNameTemplate: "logs/{name}-{index}{ext}"},
+		{LogName: "app.log", RollNameTemplate: "{name}-{time}{ext}", RollLogBySize: 1024},
+		{LogName: "app.log", RollNameTemplate: "{name}-{time}.{index}{ext}", RollLogBySize: 1024},
+		{LogName: "app.log", RollNameTemplate: "{name}-{time}{ext}"},
+		{LogName: "app.log", CurrentLink: "app.log"},
+		{LogName: "app.log", CurrentLink: "../current"},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Fatalf("want error for %+v", config)
+		}
+	}
+	config := go_log.GoLogConfig{LogName: "app.log", RollNameTemplate: "{dir}/{name}-{time:2006-01-02}{ext}", RollLogByTime: "daily", CurrentLink: "app.log"}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	// 修改滚动配置时同样校验模板
+	sink, err := go_log.NewFileSink(go_log.FileSinkConfig{LogDir: t.TempDir(), LogName: "app.log", RollLogByTime: "daily", NameTemplate: "{name}-{time}{ext}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	if err = sink.SetRoll("", 0); err == nil {
+		t.Fatal("want error for {time} without roll_log_by_time")
+	}
+	if err = sink.SetRoll("hourly", 1024); err == nil {
+		t.Fatal("want error for rolling by size without {index}")
+	}
+	if err = sink.SetRoll("hourly", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新加载的配置不满足运行中的模板时返回错误，不做任何修改
+	buf := &syncBuffer{}
+	logger, err := (&go_log.GoLogConfig{LogLevel: go_log.LoglevelInfo, LogDir: t.TempDir(), LogName: "app.log",
+		RollLogByTime: go_log.RollDaily, RollNameTemplate: "{name}-{time}{ext}", Writer: buf}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Destroy()
+	if err = logger.ApplyConfig(&go_log.GoLogConfig{LogLevel: go_log.LoglevelDebug, LogName: "app.log"}); err == nil {
+		t.Fatal("want error for {time} without roll_log_by_time")
+	}
+	logger.Debug("filtered")
+	logger.Info("kept")
+	logger.Flush()
+	if out := buf.String(); strings.Contains(out, "filtered") || !strings.Contains(out, "kept") {
+		t.Fatalf("config should not change: %q", out)
+	}
+}
+
+// TestRollNameTemplateRetention
+//
+//	@Description: 按模板滚动时只清理当前日志的压缩文件，不删除文件名前缀相同的其他日志的文件
+//	@param t
+func TestRollNameTemplateRetention(t *testing.T) {
+	dir := t.TempDir()
+	others := []string{"app-worker-20260101.1.log.zip", "app-20260101-x.1.log.zip", "app-2026.1.log.zip"}
+	for _, name := range append(others, "app-20200101.1.log.zip") {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logger := go_log.NewGoLog(&go_log.GoLogConfig{
+		LogLevel:         go_log.LoglevelInfo,
+		LogDir:           dir,
+		LogName:          "app.log",
+		RollLogByTime:    go_log.RollDaily,
+		RollLogBySize:    1,
+		RollNameTemplate: "{name}-{time:20060102}.{index}{ext}",
+		MaxBackups:       1,
+	})
+	line := strings.Repeat("x", 200)
+	for i := 0; i < 20; i++ {
+		logger.Info(line)
+	}
+	logger.Destroy()
+
+	for _, name := range others {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("%s should be kept: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "app-20200101.1.log.zip")); !os.IsNotExist(err) {
+		t.Fatal("old backup app-20200101.1.log.zip should be deleted")
+	}
+}